@@ -8,18 +8,34 @@ import (
 	mmpb "github.com/sunr3d/gomicro/money_movement"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"io"
 	"log"
 	"net/http"
+	"os"
 	"strings"
+	"time"
 )
 
 var (
 	mmClient   mmpb.MoneyMovementServiceClient
 	authClient authpb.AuthServiceClient
+	adminUsers map[string]bool // Пользователи с доступом к административным эндпоинтам (ADMIN_USER_IDS)
 )
 
+// traceHeaders - заголовки трассировки W3C, которые передаются из HTTP запроса в вызовы сервисов
+// (money_movement сохраняет их в конвертах событий)
+var traceHeaders = []string{"traceparent", "tracestate"}
+
 func main() {
+	// Список администраторов задается через запятую в переменной окружения ADMIN_USER_IDS
+	adminUsers = make(map[string]bool)
+	for _, userID := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		if userID = strings.TrimSpace(userID); userID != "" {
+			adminUsers[userID] = true
+		}
+	}
+
 	// Создание гРПС конекшена для сервиса Аутентификации
 	authConn, err := grpc.NewClient("auth:9000", grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
@@ -49,8 +65,26 @@ func main() {
 	mmClient = mmpb.NewMoneyMovementServiceClient(mmConn)
 
 	http.HandleFunc("/login", login)
-	http.HandleFunc("/customer/payment/auth", customerPaymentAuth)
-	http.HandleFunc("/customer/payment/capture", customerPaymentCapture)
+	http.HandleFunc("POST /token/refresh", tokenRefresh)
+	http.HandleFunc("POST /logout", logout)
+	http.HandleFunc("POST /register", register)
+	http.HandleFunc("GET /me", me)
+	http.HandleFunc("POST /me/password", mePasswordChange)
+	// Операции над платежом принимаются только методом POST,
+	// иначе их маршруты пересекаются с GET /customer/payment/{pid}
+	http.HandleFunc("POST /customer/payment/auth", customerPaymentAuth)
+	http.HandleFunc("POST /customer/payment/capture", customerPaymentCapture)
+	http.HandleFunc("POST /customer/payment/void", customerPaymentVoid)
+	http.HandleFunc("POST /customer/payment/refund", customerPaymentRefund)
+	http.HandleFunc("GET /customer/payment/{pid}", customerPaymentGet)
+	http.HandleFunc("POST /customer/fx/quote", customerFxQuote)
+	http.HandleFunc("POST /customer/wallet", customerWalletCreate)
+	http.HandleFunc("GET /customer/wallet", customerWalletGet)
+	http.HandleFunc("POST /customer/transfer", customerTransfer)
+	http.HandleFunc("POST /admin/deposit", adminDeposit)
+	http.HandleFunc("POST /admin/revoke-sessions", adminRevokeSessions)
+	http.HandleFunc("POST /merchant/payout", merchantPayoutRequest)
+	http.HandleFunc("GET /merchant/payout/{id}", merchantPayoutGet)
 
 	fmt.Println("listening on port 8080")
 	err = http.ListenAndServe(":8080", nil)
@@ -59,6 +93,17 @@ func main() {
 	}
 }
 
+// requestContext создает контекст gRPC вызова с заголовками трассировки HTTP запроса r
+func requestContext(r *http.Request) context.Context {
+	ctx := context.Background()
+	for _, name := range traceHeaders {
+		if value := r.Header.Get(name); value != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, name, value)
+		}
+	}
+	return ctx
+}
+
 // Описание хендлера login
 func login(w http.ResponseWriter, r *http.Request) {
 	// Получение логина и пароля через стандартный http метод BasicAuth()
@@ -71,7 +116,7 @@ func login(w http.ResponseWriter, r *http.Request) {
 
 	// gRPC методом GetToken получаем токен пользователя
 	// При ошибке с сервера в ответ записываем ошибку
-	ctx := context.Background()
+	ctx := requestContext(r)
 	token, err := authClient.GetToken(ctx, &authpb.Credentials{
 		UserName: userName,
 		Password: password,
@@ -83,15 +128,366 @@ func login(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	// При успешном получении токена возвращаем ответ 200 и токен
+	// При успешном получении токена возвращаем ответ 200 и JSON с парой токенов
+	writeTokenResponse(w, token)
+}
+
+// Описание хендлера обмена refresh токена на новую пару токенов
+func tokenRefresh(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок десериализации payload
+	// Объявление и создание го-структуры для десериализации JSON пейлоада
+	type refreshPayload struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	var payload refreshPayload
+
+	// Читаем тело запроса в поле body
+	// При ошибке возвращаем 500
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Переводим JSON из тела хттп запроса в нашу го-структуру
+	// При ошибке возвращаем 500
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// 2. Блок обмена токена
+	ctx := requestContext(r)
+	// Обмениваем refresh токен gRPC методом RefreshToken (auth)
+	// Токен одноразовый: предыдущий после обмена недействителен
+	// При ошибке (токен неизвестен, истек, отозван или использован повторно) отправляем ответ 401
+	token, err := authClient.RefreshToken(ctx, &authpb.RefreshTokenPayload{RefreshToken: payload.RefreshToken})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// 3. Блок формирования ответа
+	writeTokenResponse(w, token)
+}
+
+// Описание хендлера выхода: отзыв токена до истечения его срока
+func logout(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок десериализации payload
+	// Объявление и создание го-структуры для десериализации JSON пейлоада
+	// Тело необязательно: без refresh токена отзывается только access токен
+	type logoutPayload struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	var payload logoutPayload
+
+	// Читаем тело запроса в поле body
+	// При ошибке возвращаем 500
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Переводим JSON из тела хттп запроса в нашу го-структуру (если тело не пустое)
+	// При ошибке возвращаем 500
+	if len(body) > 0 {
+		err = json.Unmarshal(body, &payload)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// 3. Блок выхода
+	ctx := requestContext(r)
+	// Отзываем токены gRPC методом Logout (auth), он же проверяет access токен
+	// При ошибке с сервера отправляем ответ 401
+	_, err = authClient.Logout(ctx, &authpb.LogoutPayload{
+		Jwt:          token,
+		RefreshToken: payload.RefreshToken,
+	})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// При успешном выходе возвращаем статус 200
+	w.WriteHeader(http.StatusOK)
+}
+
+// writeTokenResponse отправляет клиенту код 200 и JSON с jwt и refresh токеном
+func writeTokenResponse(w http.ResponseWriter, token *authpb.Token) {
+	type response struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int64  `json:"expires_in"` // Срок действия access_token в секундах
+		RefreshToken string `json:"refresh_token"`
+	}
+	resp := response{
+		AccessToken:  token.Jwt,
+		TokenType:    "Bearer",
+		ExpiresIn:    token.ExpiresIn,
+		RefreshToken: token.RefreshToken,
+	}
+
+	// Переводим го-структуру в JSON формат
+	// При ошибке сериализации возвращаем 500
+	responseJSON, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Refresh токен нельзя кэшировать по пути к клиенту
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(responseJSON)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Описание хендлера регистрации пользователя
+func register(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок десериализации payload
+	// Объявление и создание го-структуры для десериализации JSON пейлоада
+	type registerPayload struct {
+		Email        string   `json:"email"`
+		Password     string   `json:"password"`
+		CreateWallet bool     `json:"create_wallet"` // Сразу создать кошелек покупателя
+		Currencies   []string `json:"currencies"`    // Валюты счетов кошелька (по умолчанию USD)
+	}
+	var payload registerPayload
+
+	// Читаем тело запроса в поле body
+	// При ошибке возвращаем 500
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Переводим JSON из тела хттп запроса в нашу го-структуру
+	// При ошибке возвращаем 500
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// 2. Блок регистрации
+	ctx := requestContext(r)
+	// Регистрируем пользователя gRPC методом Register (auth)
+	// Формат email и политику паролей проверяет сервис аутентификации
+	// При ошибке записываем в ответ текст ошибки
+	user, err := authClient.Register(ctx, &authpb.RegisterPayload{
+		UserName: payload.Email,
+		Password: payload.Password,
+	})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+
+	// 3. Блок создания кошелька
+	// Пользователь уже зарегистрирован, поэтому ошибка создания кошелька не отменяет регистрацию:
+	// кошелек можно создать позже через POST /customer/wallet
+	var wallet any
+	if payload.CreateWallet {
+		ctx = requestContext(r)
+		// Создаем кошелек покупателя gRPC методом CreateWallet (money_movement)
+		created, err := mmClient.CreateWallet(ctx, &mmpb.CreateWalletPayload{
+			UserId:     user.UserID,
+			WalletType: "CUSTOMER",
+			Currencies: payload.Currencies,
+		})
+		if err != nil {
+			log.Printf("user %s: create wallet: %v\n", user.UserID, err)
+		} else {
+			wallet = walletResponse(created)
+		}
+	}
+
+	// 4. Блок формирования ответа
+	// Создание Го-структуры ответа с пользователем и кошельком (если создан)
+	type response struct {
+		User   any `json:"user"`
+		Wallet any `json:"wallet,omitempty"`
+	}
+	resp := response{
+		User:   userResponse(user),
+		Wallet: wallet,
+	}
+
+	// Переводим го-структуру в JSON формат
+	// При ошибке сериализации возвращаем 500
+	responseJSON, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Отправляем с сервера код 200 и JSON с пользователем
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(responseJSON)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Описание хендлера получения профиля владельца токена
+func me(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок получения профиля
+	ctx := requestContext(r)
+	// Получаем профиль gRPC методом GetUser (auth), он же проверяет токен
+	// При ошибке с сервера отправляем ответ 401
+	user, err := authClient.GetUser(ctx, &authpb.Token{Jwt: token})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// 3. Блок формирования ответа
+	// Переводим пользователя в JSON формат
+	// При ошибке сериализации возвращаем 500
+	responseJSON, err := json.Marshal(userResponse(user))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Отправляем с сервера код 200 и JSON с профилем
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_, err = w.Write([]byte(token.Jwt))
+	_, err = w.Write(responseJSON)
 	if err != nil {
 		log.Println(err)
 	}
 }
 
+// Описание хендлера смены пароля владельца токена
+func mePasswordChange(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок десериализации payload
+	// Объявление и создание го-структуры для десериализации JSON пейлоада
+	type changePasswordPayload struct {
+		OldPassword string `json:"old_password"`
+		NewPassword string `json:"new_password"`
+	}
+	var payload changePasswordPayload
+
+	// Читаем тело запроса в поле body
+	// При ошибке возвращаем 500
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Переводим JSON из тела хттп запроса в нашу го-структуру
+	// При ошибке возвращаем 500
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// 3. Блок смены пароля
+	ctx := requestContext(r)
+	// Меняем пароль gRPC методом ChangePassword (auth), он же проверяет токен и текущий пароль
+	// При ошибке записываем в ответ текст ошибки
+	_, err = authClient.ChangePassword(ctx, &authpb.ChangePasswordPayload{
+		Jwt:         token,
+		OldPassword: payload.OldPassword,
+		NewPassword: payload.NewPassword,
+	})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+
+	// При успешной смене возвращаем статус 200
+	w.WriteHeader(http.StatusOK)
+}
+
+// userResponse переводит пользователя из protobuf в го-структуру для JSON ответа
+func userResponse(user *authpb.User) any {
+	type response struct {
+		UserID    string    `json:"user_id"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	return response{
+		UserID:    user.UserID,
+		CreatedAt: user.CreatedAt.AsTime(),
+	}
+}
+
 // Описание хендлера авторизации платежа
 func customerPaymentAuth(w http.ResponseWriter, r *http.Request) {
 	// 1. Блок проверки Authorization заголовка
@@ -114,7 +510,7 @@ func customerPaymentAuth(w http.ResponseWriter, r *http.Request) {
 	token := strings.TrimPrefix(authHeader, "Bearer ")
 
 	// 2. Блок валидации токена
-	ctx := context.Background()
+	ctx := requestContext(r)
 	// Валидируем токен gRPC методом ValidateToken
 	// При ошибке с сервера отправляем ответ 401
 	_, err := authClient.ValidateToken(ctx, &authpb.Token{Jwt: token})
@@ -125,11 +521,17 @@ func customerPaymentAuth(w http.ResponseWriter, r *http.Request) {
 
 	// 3. Блок десериализации payload
 	// Объявление и создание го-структуры для десериализации JSON пейлоада
-	type authorizePayload struct {
-		CustomerWalletUserID string `json:"customer_wallet_user_id"`
+	type merchantSplit struct {
 		MerchantWalletUserID string `json:"merchant_wallet_user_id"`
 		Cents                int64  `json:"cents"`
-		Currency             string `json:"currency"`
+	}
+	type authorizePayload struct {
+		CustomerWalletUserID string          `json:"customer_wallet_user_id"`
+		MerchantWalletUserID string          `json:"merchant_wallet_user_id"`
+		Cents                int64           `json:"cents"`
+		Currency             string          `json:"currency"`
+		FxQuoteID            string          `json:"fx_quote_id"` // Котировка для оплаты со счета в другой валюте
+		Splits               []merchantSplit `json:"splits"`      // Доли продавцов маркетплейса (вместо merchant_wallet_user_id)
 	}
 	var payload authorizePayload
 
@@ -150,14 +552,26 @@ func customerPaymentAuth(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 4. Блок авторизации платежа
-	ctx = context.Background()
+	ctx = requestContext(r)
+	// Переводим доли продавцов в protobuf (у обычного платежа их нет)
+	splits := make([]*mmpb.MerchantSplit, 0, len(payload.Splits))
+	for _, s := range payload.Splits {
+		splits = append(splits, &mmpb.MerchantSplit{
+			MerchantWalletUserId: s.MerchantWalletUserID,
+			Cents:                s.Cents,
+		})
+	}
 	// Авторизуем транзакцию gRPC методом Authorize (money_movement)
+	// Заголовок Idempotency-Key защищает от повторной авторизации при ретраях клиента
 	// При ошибке записываем в ответ текст ошибки
 	ar, err := mmClient.Authorize(ctx, &mmpb.AuthorizePayload{
 		CustomerWalletUserID: payload.CustomerWalletUserID,
 		MerchantWalletUserID: payload.MerchantWalletUserID,
 		Cents:                payload.Cents,
 		Currency:             payload.Currency,
+		IdempotencyKey:       r.Header.Get("Idempotency-Key"),
+		FxQuoteId:            payload.FxQuoteID,
+		Splits:               splits,
 	})
 	if err != nil {
 		_, writeErr := w.Write([]byte(err.Error()))
@@ -218,7 +632,7 @@ func customerPaymentCapture(w http.ResponseWriter, r *http.Request) {
 	token := strings.TrimPrefix(authHeader, "Bearer ")
 
 	// 2. Блок валидации токена
-	ctx := context.Background()
+	ctx := requestContext(r)
 	// Валидируем токен gRPC методом ValidateToken
 	// При ошибке с сервера отправляем ответ 401
 	_, err := authClient.ValidateToken(ctx, &authpb.Token{Jwt: token})
@@ -230,7 +644,10 @@ func customerPaymentCapture(w http.ResponseWriter, r *http.Request) {
 	// 3. Блок десериализации payload
 	// Объявление и создание го-структуры для десериализации JSON пейлоада
 	type capturePayload struct {
-		Pid string `json:"pid"`
+		Pid                  string `json:"pid"`
+		Cents                int64  `json:"cents"`
+		FinalCapture         bool   `json:"final_capture"`
+		MerchantWalletUserID string `json:"merchant_wallet_user_id"` // Подтверждение доли одного продавца
 	}
 	var payload capturePayload
 
@@ -251,10 +668,17 @@ func customerPaymentCapture(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 4. Блок авторизации платежа
-	ctx = context.Background()
+	ctx = requestContext(r)
 	// Захватываем транзакцию gRPC методом Capture (money_movement)
+	// Заголовок Idempotency-Key защищает от повторного подтверждения при ретраях клиента
 	// При ошибке записываем в ответ текст ошибки
-	_, err = mmClient.Capture(ctx, &mmpb.CapturePayload{Pid: payload.Pid})
+	_, err = mmClient.Capture(ctx, &mmpb.CapturePayload{
+		Pid:                  payload.Pid,
+		Cents:                payload.Cents,
+		FinalCapture:         payload.FinalCapture,
+		IdempotencyKey:       r.Header.Get("Idempotency-Key"),
+		MerchantWalletUserId: payload.MerchantWalletUserID,
+	})
 	if err != nil {
 		_, writeErr := w.Write([]byte(err.Error()))
 		if writeErr != nil {
@@ -266,3 +690,1117 @@ func customerPaymentCapture(w http.ResponseWriter, r *http.Request) {
 	// При успешном захвате возвращаем статус 200
 	w.WriteHeader(http.StatusOK)
 }
+
+// Описание хендлера отмены авторизованного платежа
+func customerPaymentVoid(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок валидации токена
+	ctx := requestContext(r)
+	// Валидируем токен gRPC методом ValidateToken
+	// При ошибке с сервера отправляем ответ 401
+	_, err := authClient.ValidateToken(ctx, &authpb.Token{Jwt: token})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// 3. Блок десериализации payload
+	// Объявление и создание го-структуры для десериализации JSON пейлоада
+	type voidPayload struct {
+		Pid string `json:"pid"`
+	}
+	var payload voidPayload
+
+	// Читаем тело запроса в поле body
+	// При ошибке возвращаем 500
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Переводим JSON из тела хттп запроса в нашу го-структуру
+	// При ошибке возвращаем 500
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// 4. Блок отмены платежа
+	ctx = requestContext(r)
+	// Отменяем авторизацию gRPC методом Void (money_movement)
+	// При ошибке записываем в ответ текст ошибки
+	_, err = mmClient.Void(ctx, &mmpb.VoidPayload{Pid: payload.Pid})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+
+	// При успешной отмене возвращаем статус 200
+	w.WriteHeader(http.StatusOK)
+}
+
+// Описание хендлера возврата средств по подтвержденному платежу
+func customerPaymentRefund(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок валидации токена
+	ctx := requestContext(r)
+	// Валидируем токен gRPC методом ValidateToken
+	// При ошибке с сервера отправляем ответ 401
+	user, err := authClient.ValidateToken(ctx, &authpb.Token{Jwt: token})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// 3. Блок десериализации payload
+	// Объявление и создание го-структуры для десериализации JSON пейлоада
+	type refundPayload struct {
+		Pid                  string `json:"pid"`
+		Cents                int64  `json:"cents"`
+		MerchantWalletUserID string `json:"merchant_wallet_user_id"` // Продавец, с доли которого делается возврат
+	}
+	var payload refundPayload
+
+	// Читаем тело запроса в поле body
+	// При ошибке возвращаем 500
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Переводим JSON из тела хттп запроса в нашу го-структуру
+	// При ошибке возвращаем 500
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// 4. Блок проверки прав на возврат
+	// Возврат списывается со счета продавца, поэтому его делает продавец своей доли
+	// платежа или администратор из ADMIN_USER_IDS
+	ctx = requestContext(r)
+	// Получаем платеж gRPC методом GetPayment (money_movement)
+	// При ошибке записываем в ответ текст ошибки
+	payment, err := mmClient.GetPayment(ctx, &mmpb.GetPaymentPayload{Pid: payload.Pid})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+	if !adminUsers[user.UserID] {
+		// Платежи, где пользователь не продавец, не показываем, отвечаем как на несуществующий (404)
+		if !isPaymentMerchant(payment, user.UserID) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		// Возврат с чужой доли запрещен, по умолчанию возврат делается со своей доли
+		if payload.MerchantWalletUserID == "" {
+			payload.MerchantWalletUserID = user.UserID
+		}
+		if payload.MerchantWalletUserID != user.UserID {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+	}
+
+	// 5. Блок возврата платежа
+	ctx = requestContext(r)
+	// Возвращаем средства покупателю gRPC методом Refund (money_movement)
+	// При ошибке записываем в ответ текст ошибки
+	_, err = mmClient.Refund(ctx, &mmpb.RefundPayload{
+		Pid:                  payload.Pid,
+		Cents:                payload.Cents,
+		MerchantWalletUserId: payload.MerchantWalletUserID,
+	})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+
+	// При успешном возврате возвращаем статус 200
+	w.WriteHeader(http.StatusOK)
+}
+
+// Описание хендлера получения состояния платежа
+
+// isPaymentMerchant сообщает, что пользователь userID - продавец одной из долей платежа
+func isPaymentMerchant(payment *mmpb.Payment, userID string) bool {
+	for _, s := range payment.Splits {
+		if s.MerchantWalletUserId == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func customerPaymentGet(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок валидации токена
+	ctx := requestContext(r)
+	// Валидируем токен gRPC методом ValidateToken
+	// При ошибке с сервера отправляем ответ 401
+	_, err := authClient.ValidateToken(ctx, &authpb.Token{Jwt: token})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// 3. Блок получения платежа
+	// Айди платежа берем из пути запроса (/customer/payment/{pid})
+	ctx = requestContext(r)
+	// Получаем платеж gRPC методом GetPayment (money_movement)
+	// При ошибке записываем в ответ текст ошибки
+	payment, err := mmClient.GetPayment(ctx, &mmpb.GetPaymentPayload{Pid: r.PathValue("pid")})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+
+	// 4. Блок формирования ответа
+	// Создание Го-структур ответа с состоянием платежа и историей его транзакций
+	type transaction struct {
+		Type           string    `json:"type"`
+		SrcUserID      string    `json:"src_user_id"`
+		DstUserID      string    `json:"dst_user_id"`
+		SrcAccountType string    `json:"src_account_type"`
+		DstAccountType string    `json:"dst_account_type"`
+		Cents          int64     `json:"cents"`
+		Currency       string    `json:"currency"`
+		FxQuoteID      string    `json:"fx_quote_id,omitempty"`
+		FxRate         string    `json:"fx_rate,omitempty"`
+		CreatedAt      time.Time `json:"created_at"`
+	}
+	type split struct {
+		MerchantWalletUserID string `json:"merchant_wallet_user_id"`
+		AuthorizedCents      int64  `json:"authorized_cents"`
+		CapturedCents        int64  `json:"captured_cents"`
+		RefundedCents        int64  `json:"refunded_cents"`
+	}
+	type response struct {
+		Pid                  string        `json:"pid"`
+		Status               string        `json:"status"`
+		CustomerWalletUserID string        `json:"customer_wallet_user_id"`
+		MerchantWalletUserID string        `json:"merchant_wallet_user_id"`
+		Currency             string        `json:"currency"`
+		AuthorizedCents      int64         `json:"authorized_cents"`
+		CapturedCents        int64         `json:"captured_cents"`
+		RefundedCents        int64         `json:"refunded_cents"`
+		History              []transaction `json:"history"`
+		CreatedAt            time.Time     `json:"created_at"`
+		UpdatedAt            time.Time     `json:"updated_at"`
+		ExpiresAt            time.Time     `json:"expires_at"`
+		FundingCurrency      string        `json:"funding_currency"`
+		FundingCents         int64         `json:"funding_cents"`
+		FxQuoteID            string        `json:"fx_quote_id,omitempty"`
+		Splits               []split       `json:"splits"`
+	}
+	resp := response{
+		Pid:                  payment.Pid,
+		Status:               payment.Status,
+		CustomerWalletUserID: payment.CustomerWalletUserID,
+		MerchantWalletUserID: payment.MerchantWalletUserID,
+		Currency:             payment.Currency,
+		AuthorizedCents:      payment.AuthorizedCents,
+		CapturedCents:        payment.CapturedCents,
+		RefundedCents:        payment.RefundedCents,
+		History:              make([]transaction, 0, len(payment.History)),
+		CreatedAt:            payment.CreatedAt.AsTime(),
+		UpdatedAt:            payment.UpdatedAt.AsTime(),
+		ExpiresAt:            payment.ExpiresAt.AsTime(),
+		FundingCurrency:      payment.FundingCurrency,
+		FundingCents:         payment.FundingCents,
+		FxQuoteID:            payment.FxQuoteId,
+		Splits:               make([]split, 0, len(payment.Splits)),
+	}
+	for _, s := range payment.Splits {
+		resp.Splits = append(resp.Splits, split{
+			MerchantWalletUserID: s.MerchantWalletUserId,
+			AuthorizedCents:      s.AuthorizedCents,
+			CapturedCents:        s.CapturedCents,
+			RefundedCents:        s.RefundedCents,
+		})
+	}
+	for _, t := range payment.History {
+		resp.History = append(resp.History, transaction{
+			Type:           t.Type,
+			SrcUserID:      t.SrcUserID,
+			DstUserID:      t.DstUserID,
+			SrcAccountType: t.SrcAccountType,
+			DstAccountType: t.DstAccountType,
+			Cents:          t.Cents,
+			Currency:       t.Currency,
+			FxQuoteID:      t.FxQuoteId,
+			FxRate:         t.FxRate,
+			CreatedAt:      t.CreatedAt.AsTime(),
+		})
+	}
+
+	// Переводим го-структуру в JSON формат
+	// При ошибке сериализации возвращаем 500
+	responseJSON, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Отправляем с сервера код 200 и JSON с состоянием платежа
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(responseJSON)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+}
+
+// Описание хендлера получения котировки конвертации валют
+func customerFxQuote(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок валидации токена
+	ctx := requestContext(r)
+	// Валидируем токен gRPC методом ValidateToken
+	// При ошибке с сервера отправляем ответ 401
+	_, err := authClient.ValidateToken(ctx, &authpb.Token{Jwt: token})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// 3. Блок десериализации payload
+	// Объявление и создание го-структуры для десериализации JSON пейлоада
+	type quotePayload struct {
+		SellCurrency string `json:"sell_currency"` // Валюта счета покупателя
+		BuyCurrency  string `json:"buy_currency"`  // Валюта платежа
+		BuyCents     int64  `json:"buy_cents"`     // Сумма платежа
+	}
+	var payload quotePayload
+
+	// Читаем тело запроса в поле body
+	// При ошибке возвращаем 500
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Переводим JSON из тела хттп запроса в нашу го-структуру
+	// При ошибке возвращаем 500
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// 4. Блок получения котировки
+	ctx = requestContext(r)
+	// Получаем котировку gRPC методом CreateFxQuote (money_movement)
+	// При ошибке записываем в ответ текст ошибки
+	quote, err := mmClient.CreateFxQuote(ctx, &mmpb.FxQuotePayload{
+		SellCurrency: payload.SellCurrency,
+		BuyCurrency:  payload.BuyCurrency,
+		BuyCents:     payload.BuyCents,
+	})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+
+	// 5. Блок формирования ответа
+	// Создание Го-структуры ответа с котировкой
+	type response struct {
+		QuoteID      string    `json:"quote_id"`
+		SellCurrency string    `json:"sell_currency"`
+		BuyCurrency  string    `json:"buy_currency"`
+		SellCents    int64     `json:"sell_cents"`
+		BuyCents     int64     `json:"buy_cents"`
+		Rate         string    `json:"rate"`
+		MidRate      string    `json:"mid_rate"`
+		SpreadBps    int32     `json:"spread_bps"`
+		ExpiresAt    time.Time `json:"expires_at"`
+	}
+	resp := response{
+		QuoteID:      quote.QuoteId,
+		SellCurrency: quote.SellCurrency,
+		BuyCurrency:  quote.BuyCurrency,
+		SellCents:    quote.SellCents,
+		BuyCents:     quote.BuyCents,
+		Rate:         quote.Rate,
+		MidRate:      quote.MidRate,
+		SpreadBps:    quote.SpreadBps,
+		ExpiresAt:    quote.ExpiresAt.AsTime(),
+	}
+
+	// Переводим го-структуру в JSON формат
+	// При ошибке сериализации возвращаем 500
+	responseJSON, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Отправляем с сервера код 200 и JSON с котировкой
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(responseJSON)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Описание хендлера создания кошелька пользователя
+func customerWalletCreate(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок валидации токена
+	ctx := requestContext(r)
+	// Валидируем токен gRPC методом ValidateToken
+	// Кошелек всегда принадлежит владельцу токена
+	// При ошибке с сервера отправляем ответ 401
+	user, err := authClient.ValidateToken(ctx, &authpb.Token{Jwt: token})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// 3. Блок десериализации payload
+	// Объявление и создание го-структуры для десериализации JSON пейлоада
+	type createWalletPayload struct {
+		WalletType string   `json:"wallet_type"` // CUSTOMER или MERCHANT
+		Currencies []string `json:"currencies"`  // Валюты счетов (по умолчанию USD)
+	}
+	var payload createWalletPayload
+
+	// Читаем тело запроса в поле body
+	// При ошибке возвращаем 500
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Переводим JSON из тела хттп запроса в нашу го-структуру
+	// При ошибке возвращаем 500
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// 4. Блок создания кошелька
+	ctx = requestContext(r)
+	// Создаем кошелек со счетами gRPC методом CreateWallet (money_movement)
+	// При ошибке записываем в ответ текст ошибки
+	wallet, err := mmClient.CreateWallet(ctx, &mmpb.CreateWalletPayload{
+		UserId:     user.UserID,
+		WalletType: payload.WalletType,
+		Currencies: payload.Currencies,
+	})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+
+	// 5. Блок формирования ответа
+	// Переводим го-структуру в JSON формат
+	// При ошибке сериализации возвращаем 500
+	responseJSON, err := json.Marshal(walletResponse(wallet))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Отправляем с сервера код 200 и JSON с кошельком и балансами счетов
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(responseJSON)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Описание хендлера получения кошелька пользователя
+func customerWalletGet(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок валидации токена
+	ctx := requestContext(r)
+	// Валидируем токен gRPC методом ValidateToken
+	// Кошелек всегда принадлежит владельцу токена
+	// При ошибке с сервера отправляем ответ 401
+	user, err := authClient.ValidateToken(ctx, &authpb.Token{Jwt: token})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// 3. Блок получения кошелька
+	ctx = requestContext(r)
+	// Получаем кошелек владельца токена gRPC методом GetWallet (money_movement)
+	// При ошибке записываем в ответ текст ошибки
+	wallet, err := mmClient.GetWallet(ctx, &mmpb.GetWalletPayload{UserId: user.UserID})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+
+	// 4. Блок формирования ответа
+	// Переводим го-структуру в JSON формат
+	// При ошибке сериализации возвращаем 500
+	responseJSON, err := json.Marshal(walletResponse(wallet))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Отправляем с сервера код 200 и JSON с кошельком и балансами счетов
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(responseJSON)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// walletResponse переводит кошелек из protobuf в го-структуру для JSON ответа
+func walletResponse(wallet *mmpb.Wallet) any {
+	type account struct {
+		AccountType string `json:"account_type"`
+		Currency    string `json:"currency"`
+		Cents       int64  `json:"cents"`
+	}
+	type response struct {
+		UserID     string    `json:"user_id"`
+		WalletType string    `json:"wallet_type"`
+		Accounts   []account `json:"accounts"`
+	}
+	resp := response{
+		UserID:     wallet.UserId,
+		WalletType: wallet.WalletType,
+		Accounts:   make([]account, 0, len(wallet.Accounts)),
+	}
+	for _, a := range wallet.Accounts {
+		resp.Accounts = append(resp.Accounts, account{
+			AccountType: a.AccountType,
+			Currency:    a.Currency,
+			Cents:       a.Cents,
+		})
+	}
+	return resp
+}
+
+// Описание хендлера перевода денег другому покупателю
+func customerTransfer(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок валидации токена
+	ctx := requestContext(r)
+	// Валидируем токен gRPC методом ValidateToken
+	// При ошибке с сервера отправляем ответ 401
+	user, err := authClient.ValidateToken(ctx, &authpb.Token{Jwt: token})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// 3. Блок десериализации payload
+	// Объявление и создание го-структуры для десериализации JSON пейлоада
+	type transferPayload struct {
+		SrcUserID string `json:"src_user_id"` // Необязателен, по умолчанию - владелец токена
+		DstUserID string `json:"dst_user_id"`
+		Cents     int64  `json:"cents"`
+		Currency  string `json:"currency"`
+		Memo      string `json:"memo"`
+	}
+	var payload transferPayload
+
+	// Читаем тело запроса в поле body
+	// При ошибке возвращаем 500
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Переводим JSON из тела хттп запроса в нашу го-структуру
+	// При ошибке возвращаем 500
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Переводить деньги можно только со своего кошелька
+	// Если в запросе указан чужой кошелек отправителя, возвращаем 403
+	if payload.SrcUserID == "" {
+		payload.SrcUserID = user.UserID
+	}
+	if payload.SrcUserID != user.UserID {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	// 4. Блок перевода
+	ctx = requestContext(r)
+	// Переводим деньги gRPC методом Transfer (money_movement)
+	// Заголовок Idempotency-Key защищает от повторного перевода при ретраях клиента
+	// При ошибке записываем в ответ текст ошибки
+	tr, err := mmClient.Transfer(ctx, &mmpb.TransferPayload{
+		SrcUserId:      payload.SrcUserID,
+		DstUserId:      payload.DstUserID,
+		Cents:          payload.Cents,
+		Currency:       payload.Currency,
+		Memo:           payload.Memo,
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+	})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+
+	// 5. Блок формирования ответа
+	// Создание Го-структуры ответа с айдишником перевода
+	type response struct {
+		TransferID string `json:"transfer_id"`
+	}
+	resp := response{
+		TransferID: tr.TransferId,
+	}
+
+	// Переводим го-структуру в JSON формат
+	// При ошибке сериализации возвращаем 500
+	responseJSON, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Отправляем с сервера код 200 и JSON с айди перевода
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(responseJSON)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Описание хендлера пополнения счета покупателя (только для администраторов)
+func adminDeposit(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок валидации токена и прав администратора
+	ctx := requestContext(r)
+	// Валидируем токен gRPC методом ValidateToken
+	// При ошибке с сервера отправляем ответ 401
+	user, err := authClient.ValidateToken(ctx, &authpb.Token{Jwt: token})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Пополнять счета могут только администраторы из ADMIN_USER_IDS
+	// Остальным пользователям возвращаем 403
+	if !adminUsers[user.UserID] {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	// 3. Блок десериализации payload
+	// Объявление и создание го-структуры для десериализации JSON пейлоада
+	type depositPayload struct {
+		UserID   string `json:"user_id"`
+		Cents    int64  `json:"cents"`
+		Currency string `json:"currency"`
+	}
+	var payload depositPayload
+
+	// Читаем тело запроса в поле body
+	// При ошибке возвращаем 500
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Переводим JSON из тела хттп запроса в нашу го-структуру
+	// При ошибке возвращаем 500
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// 4. Блок пополнения счета
+	ctx = requestContext(r)
+	// Пополняем базовый счет покупателя gRPC методом Deposit (money_movement)
+	// Заголовок Idempotency-Key защищает от повторного пополнения при ретраях клиента
+	// При ошибке записываем в ответ текст ошибки
+	dr, err := mmClient.Deposit(ctx, &mmpb.DepositPayload{
+		UserId:         payload.UserID,
+		Cents:          payload.Cents,
+		Currency:       payload.Currency,
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+	})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+
+	// 5. Блок формирования ответа
+	// Создание Го-структуры ответа с айдишником пополнения
+	type response struct {
+		DepositID string `json:"deposit_id"`
+	}
+	resp := response{
+		DepositID: dr.DepositId,
+	}
+
+	// Переводим го-структуру в JSON формат
+	// При ошибке сериализации возвращаем 500
+	responseJSON, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Отправляем с сервера код 200 и JSON с айди пополнения
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(responseJSON)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Описание хендлера отзыва всех сессий пользователя (только для администраторов)
+func adminRevokeSessions(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок валидации токена и прав администратора
+	ctx := requestContext(r)
+	// Валидируем токен gRPC методом ValidateToken
+	// При ошибке с сервера отправляем ответ 401
+	user, err := authClient.ValidateToken(ctx, &authpb.Token{Jwt: token})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Отзывать сессии могут только администраторы из ADMIN_USER_IDS
+	// Остальным пользователям возвращаем 403
+	if !adminUsers[user.UserID] {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	// 3. Блок десериализации payload
+	// Объявление и создание го-структуры для десериализации JSON пейлоада
+	type revokeSessionsPayload struct {
+		UserID string `json:"user_id"`
+	}
+	var payload revokeSessionsPayload
+
+	// Читаем тело запроса в поле body
+	// При ошибке возвращаем 500
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Переводим JSON из тела хттп запроса в нашу го-структуру
+	// При ошибке возвращаем 500
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// 4. Блок отзыва сессий
+	ctx = requestContext(r)
+	// Отзываем все токены пользователя gRPC методом RevokeUserSessions (auth)
+	// При ошибке записываем в ответ текст ошибки
+	_, err = authClient.RevokeUserSessions(ctx, &authpb.RevokeUserSessionsPayload{UserID: payload.UserID})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+
+	// При успешном отзыве возвращаем статус 200
+	w.WriteHeader(http.StatusOK)
+}
+
+// Описание хендлера запроса выплаты продавцу на банковский счет
+func merchantPayoutRequest(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок валидации токена
+	ctx := requestContext(r)
+	// Валидируем токен gRPC методом ValidateToken
+	// При ошибке с сервера отправляем ответ 401
+	user, err := authClient.ValidateToken(ctx, &authpb.Token{Jwt: token})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// 3. Блок десериализации payload
+	// Объявление и создание го-структуры для десериализации JSON пейлоада
+	type payoutPayload struct {
+		Cents    int64  `json:"cents"`
+		Currency string `json:"currency"`
+	}
+	var payload payoutPayload
+
+	// Читаем тело запроса в поле body
+	// При ошибке возвращаем 500
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Переводим JSON из тела хттп запроса в нашу го-структуру
+	// При ошибке возвращаем 500
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// 4. Блок запроса выплаты
+	ctx = requestContext(r)
+	// Запрашиваем выплату gRPC методом RequestPayout (money_movement)
+	// Продавец берется из токена: выплату можно запросить только со своего кошелька
+	// Заголовок Idempotency-Key защищает от повторной выплаты при ретраях клиента
+	// При ошибке записываем в ответ текст ошибки
+	payout, err := mmClient.RequestPayout(ctx, &mmpb.RequestPayoutPayload{
+		MerchantUserId: user.UserID,
+		Cents:          payload.Cents,
+		Currency:       payload.Currency,
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+	})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+
+	// 5. Блок формирования ответа
+	// Переводим выплату в JSON формат
+	// При ошибке сериализации возвращаем 500
+	responseJSON, err := json.Marshal(payoutResponse(payout))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Отправляем с сервера код 200 и JSON с выплатой
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(responseJSON)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Описание хендлера получения состояния выплаты продавцу
+func merchantPayoutGet(w http.ResponseWriter, r *http.Request) {
+	// 1. Блок проверки Authorization заголовка
+	// Получаем заголовок Authorization стандартным http методом Header.Get()
+	// Если заголовок пустой, то с сервера возвращаем ошибку 401
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Проверяем есть ли в заголовке префикс Bearer
+	// При отсутствии возвращаем с сервера ошибку 401
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// Извлекаем стринговый токен вырезая из него "Bearer " (он нам не понадобится)
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	// 2. Блок валидации токена
+	ctx := requestContext(r)
+	// Валидируем токен gRPC методом ValidateToken
+	// При ошибке с сервера отправляем ответ 401
+	user, err := authClient.ValidateToken(ctx, &authpb.Token{Jwt: token})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// 3. Блок получения выплаты
+	// Айди выплаты берем из пути запроса (/merchant/payout/{id})
+	ctx = requestContext(r)
+	// Получаем выплату gRPC методом GetPayout (money_movement)
+	// При ошибке записываем в ответ текст ошибки
+	payout, err := mmClient.GetPayout(ctx, &mmpb.GetPayoutPayload{PayoutId: r.PathValue("id")})
+	if err != nil {
+		_, writeErr := w.Write([]byte(err.Error()))
+		if writeErr != nil {
+			log.Println(writeErr)
+		}
+		return
+	}
+
+	// Чужие выплаты не показываем, отвечаем так же, как на несуществующую (404)
+	if payout.MerchantUserId != user.UserID {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	// 4. Блок формирования ответа
+	// Переводим выплату в JSON формат
+	// При ошибке сериализации возвращаем 500
+	responseJSON, err := json.Marshal(payoutResponse(payout))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Отправляем с сервера код 200 и JSON с выплатой
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(responseJSON)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// payoutResponse переводит выплату из protobuf в го-структуру для JSON ответа
+func payoutResponse(payout *mmpb.Payout) any {
+	type response struct {
+		PayoutID       string    `json:"payout_id"`
+		MerchantUserID string    `json:"merchant_user_id"`
+		Cents          int64     `json:"cents"`
+		Currency       string    `json:"currency"`
+		Status         string    `json:"status"`
+		FailureReason  string    `json:"failure_reason,omitempty"`
+		CreatedAt      time.Time `json:"created_at"`
+		UpdatedAt      time.Time `json:"updated_at"`
+	}
+	return response{
+		PayoutID:       payout.PayoutId,
+		MerchantUserID: payout.MerchantUserId,
+		Cents:          payout.Cents,
+		Currency:       payout.Currency,
+		Status:         payout.Status,
+		FailureReason:  payout.FailureReason,
+		CreatedAt:      payout.CreatedAt.AsTime(),
+		UpdatedAt:      payout.UpdatedAt.AsTime(),
+	}
+}
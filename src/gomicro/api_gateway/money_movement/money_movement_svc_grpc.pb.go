@@ -20,8 +20,19 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MoneyMovementService_Authorize_FullMethodName = "/MoneyMovementService/Authorize"
-	MoneyMovementService_Capture_FullMethodName   = "/MoneyMovementService/Capture"
+	MoneyMovementService_Authorize_FullMethodName     = "/MoneyMovementService/Authorize"
+	MoneyMovementService_Capture_FullMethodName       = "/MoneyMovementService/Capture"
+	MoneyMovementService_Void_FullMethodName          = "/MoneyMovementService/Void"
+	MoneyMovementService_Refund_FullMethodName        = "/MoneyMovementService/Refund"
+	MoneyMovementService_GetPayment_FullMethodName    = "/MoneyMovementService/GetPayment"
+	MoneyMovementService_CreateFxQuote_FullMethodName = "/MoneyMovementService/CreateFxQuote"
+	MoneyMovementService_SetFxRates_FullMethodName    = "/MoneyMovementService/SetFxRates"
+	MoneyMovementService_CreateWallet_FullMethodName  = "/MoneyMovementService/CreateWallet"
+	MoneyMovementService_GetWallet_FullMethodName     = "/MoneyMovementService/GetWallet"
+	MoneyMovementService_Deposit_FullMethodName       = "/MoneyMovementService/Deposit"
+	MoneyMovementService_RequestPayout_FullMethodName = "/MoneyMovementService/RequestPayout"
+	MoneyMovementService_GetPayout_FullMethodName     = "/MoneyMovementService/GetPayout"
+	MoneyMovementService_Transfer_FullMethodName      = "/MoneyMovementService/Transfer"
 )
 
 // MoneyMovementServiceClient is the client API for MoneyMovementService service.
@@ -30,6 +41,17 @@ const (
 type MoneyMovementServiceClient interface {
 	Authorize(ctx context.Context, in *AuthorizePayload, opts ...grpc.CallOption) (*AuthorizeResponse, error)
 	Capture(ctx context.Context, in *CapturePayload, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Void(ctx context.Context, in *VoidPayload, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Refund(ctx context.Context, in *RefundPayload, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetPayment(ctx context.Context, in *GetPaymentPayload, opts ...grpc.CallOption) (*Payment, error)
+	CreateFxQuote(ctx context.Context, in *FxQuotePayload, opts ...grpc.CallOption) (*FxQuote, error)
+	SetFxRates(ctx context.Context, in *SetFxRatesPayload, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CreateWallet(ctx context.Context, in *CreateWalletPayload, opts ...grpc.CallOption) (*Wallet, error)
+	GetWallet(ctx context.Context, in *GetWalletPayload, opts ...grpc.CallOption) (*Wallet, error)
+	Deposit(ctx context.Context, in *DepositPayload, opts ...grpc.CallOption) (*DepositResponse, error)
+	RequestPayout(ctx context.Context, in *RequestPayoutPayload, opts ...grpc.CallOption) (*Payout, error)
+	GetPayout(ctx context.Context, in *GetPayoutPayload, opts ...grpc.CallOption) (*Payout, error)
+	Transfer(ctx context.Context, in *TransferPayload, opts ...grpc.CallOption) (*TransferResponse, error)
 }
 
 type moneyMovementServiceClient struct {
@@ -60,12 +82,133 @@ func (c *moneyMovementServiceClient) Capture(ctx context.Context, in *CapturePay
 	return out, nil
 }
 
+func (c *moneyMovementServiceClient) Void(ctx context.Context, in *VoidPayload, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, MoneyMovementService_Void_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *moneyMovementServiceClient) Refund(ctx context.Context, in *RefundPayload, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, MoneyMovementService_Refund_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *moneyMovementServiceClient) GetPayment(ctx context.Context, in *GetPaymentPayload, opts ...grpc.CallOption) (*Payment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Payment)
+	err := c.cc.Invoke(ctx, MoneyMovementService_GetPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *moneyMovementServiceClient) CreateFxQuote(ctx context.Context, in *FxQuotePayload, opts ...grpc.CallOption) (*FxQuote, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FxQuote)
+	err := c.cc.Invoke(ctx, MoneyMovementService_CreateFxQuote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *moneyMovementServiceClient) SetFxRates(ctx context.Context, in *SetFxRatesPayload, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, MoneyMovementService_SetFxRates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *moneyMovementServiceClient) CreateWallet(ctx context.Context, in *CreateWalletPayload, opts ...grpc.CallOption) (*Wallet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Wallet)
+	err := c.cc.Invoke(ctx, MoneyMovementService_CreateWallet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *moneyMovementServiceClient) GetWallet(ctx context.Context, in *GetWalletPayload, opts ...grpc.CallOption) (*Wallet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Wallet)
+	err := c.cc.Invoke(ctx, MoneyMovementService_GetWallet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *moneyMovementServiceClient) Deposit(ctx context.Context, in *DepositPayload, opts ...grpc.CallOption) (*DepositResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DepositResponse)
+	err := c.cc.Invoke(ctx, MoneyMovementService_Deposit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *moneyMovementServiceClient) RequestPayout(ctx context.Context, in *RequestPayoutPayload, opts ...grpc.CallOption) (*Payout, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Payout)
+	err := c.cc.Invoke(ctx, MoneyMovementService_RequestPayout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *moneyMovementServiceClient) GetPayout(ctx context.Context, in *GetPayoutPayload, opts ...grpc.CallOption) (*Payout, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Payout)
+	err := c.cc.Invoke(ctx, MoneyMovementService_GetPayout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *moneyMovementServiceClient) Transfer(ctx context.Context, in *TransferPayload, opts ...grpc.CallOption) (*TransferResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransferResponse)
+	err := c.cc.Invoke(ctx, MoneyMovementService_Transfer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MoneyMovementServiceServer is the server API for MoneyMovementService service.
 // All implementations must embed UnimplementedMoneyMovementServiceServer
 // for forward compatibility.
 type MoneyMovementServiceServer interface {
 	Authorize(context.Context, *AuthorizePayload) (*AuthorizeResponse, error)
 	Capture(context.Context, *CapturePayload) (*emptypb.Empty, error)
+	Void(context.Context, *VoidPayload) (*emptypb.Empty, error)
+	Refund(context.Context, *RefundPayload) (*emptypb.Empty, error)
+	GetPayment(context.Context, *GetPaymentPayload) (*Payment, error)
+	CreateFxQuote(context.Context, *FxQuotePayload) (*FxQuote, error)
+	SetFxRates(context.Context, *SetFxRatesPayload) (*emptypb.Empty, error)
+	CreateWallet(context.Context, *CreateWalletPayload) (*Wallet, error)
+	GetWallet(context.Context, *GetWalletPayload) (*Wallet, error)
+	Deposit(context.Context, *DepositPayload) (*DepositResponse, error)
+	RequestPayout(context.Context, *RequestPayoutPayload) (*Payout, error)
+	GetPayout(context.Context, *GetPayoutPayload) (*Payout, error)
+	Transfer(context.Context, *TransferPayload) (*TransferResponse, error)
 	mustEmbedUnimplementedMoneyMovementServiceServer()
 }
 
@@ -82,6 +225,39 @@ func (UnimplementedMoneyMovementServiceServer) Authorize(context.Context, *Autho
 func (UnimplementedMoneyMovementServiceServer) Capture(context.Context, *CapturePayload) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Capture not implemented")
 }
+func (UnimplementedMoneyMovementServiceServer) Void(context.Context, *VoidPayload) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Void not implemented")
+}
+func (UnimplementedMoneyMovementServiceServer) Refund(context.Context, *RefundPayload) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Refund not implemented")
+}
+func (UnimplementedMoneyMovementServiceServer) GetPayment(context.Context, *GetPaymentPayload) (*Payment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPayment not implemented")
+}
+func (UnimplementedMoneyMovementServiceServer) CreateFxQuote(context.Context, *FxQuotePayload) (*FxQuote, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFxQuote not implemented")
+}
+func (UnimplementedMoneyMovementServiceServer) SetFxRates(context.Context, *SetFxRatesPayload) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetFxRates not implemented")
+}
+func (UnimplementedMoneyMovementServiceServer) CreateWallet(context.Context, *CreateWalletPayload) (*Wallet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWallet not implemented")
+}
+func (UnimplementedMoneyMovementServiceServer) GetWallet(context.Context, *GetWalletPayload) (*Wallet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWallet not implemented")
+}
+func (UnimplementedMoneyMovementServiceServer) Deposit(context.Context, *DepositPayload) (*DepositResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deposit not implemented")
+}
+func (UnimplementedMoneyMovementServiceServer) RequestPayout(context.Context, *RequestPayoutPayload) (*Payout, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestPayout not implemented")
+}
+func (UnimplementedMoneyMovementServiceServer) GetPayout(context.Context, *GetPayoutPayload) (*Payout, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPayout not implemented")
+}
+func (UnimplementedMoneyMovementServiceServer) Transfer(context.Context, *TransferPayload) (*TransferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transfer not implemented")
+}
 func (UnimplementedMoneyMovementServiceServer) mustEmbedUnimplementedMoneyMovementServiceServer() {}
 func (UnimplementedMoneyMovementServiceServer) testEmbeddedByValue()                              {}
 
@@ -139,6 +315,204 @@ func _MoneyMovementService_Capture_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MoneyMovementService_Void_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VoidPayload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MoneyMovementServiceServer).Void(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MoneyMovementService_Void_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MoneyMovementServiceServer).Void(ctx, req.(*VoidPayload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MoneyMovementService_Refund_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefundPayload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MoneyMovementServiceServer).Refund(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MoneyMovementService_Refund_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MoneyMovementServiceServer).Refund(ctx, req.(*RefundPayload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MoneyMovementService_GetPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPaymentPayload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MoneyMovementServiceServer).GetPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MoneyMovementService_GetPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MoneyMovementServiceServer).GetPayment(ctx, req.(*GetPaymentPayload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MoneyMovementService_CreateFxQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FxQuotePayload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MoneyMovementServiceServer).CreateFxQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MoneyMovementService_CreateFxQuote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MoneyMovementServiceServer).CreateFxQuote(ctx, req.(*FxQuotePayload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MoneyMovementService_SetFxRates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetFxRatesPayload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MoneyMovementServiceServer).SetFxRates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MoneyMovementService_SetFxRates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MoneyMovementServiceServer).SetFxRates(ctx, req.(*SetFxRatesPayload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MoneyMovementService_CreateWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWalletPayload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MoneyMovementServiceServer).CreateWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MoneyMovementService_CreateWallet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MoneyMovementServiceServer).CreateWallet(ctx, req.(*CreateWalletPayload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MoneyMovementService_GetWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWalletPayload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MoneyMovementServiceServer).GetWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MoneyMovementService_GetWallet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MoneyMovementServiceServer).GetWallet(ctx, req.(*GetWalletPayload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MoneyMovementService_Deposit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DepositPayload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MoneyMovementServiceServer).Deposit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MoneyMovementService_Deposit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MoneyMovementServiceServer).Deposit(ctx, req.(*DepositPayload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MoneyMovementService_RequestPayout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestPayoutPayload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MoneyMovementServiceServer).RequestPayout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MoneyMovementService_RequestPayout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MoneyMovementServiceServer).RequestPayout(ctx, req.(*RequestPayoutPayload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MoneyMovementService_GetPayout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPayoutPayload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MoneyMovementServiceServer).GetPayout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MoneyMovementService_GetPayout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MoneyMovementServiceServer).GetPayout(ctx, req.(*GetPayoutPayload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MoneyMovementService_Transfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransferPayload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MoneyMovementServiceServer).Transfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MoneyMovementService_Transfer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MoneyMovementServiceServer).Transfer(ctx, req.(*TransferPayload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MoneyMovementService_ServiceDesc is the grpc.ServiceDesc for MoneyMovementService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -154,6 +528,50 @@ var MoneyMovementService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Capture",
 			Handler:    _MoneyMovementService_Capture_Handler,
 		},
+		{
+			MethodName: "Void",
+			Handler:    _MoneyMovementService_Void_Handler,
+		},
+		{
+			MethodName: "Refund",
+			Handler:    _MoneyMovementService_Refund_Handler,
+		},
+		{
+			MethodName: "GetPayment",
+			Handler:    _MoneyMovementService_GetPayment_Handler,
+		},
+		{
+			MethodName: "CreateFxQuote",
+			Handler:    _MoneyMovementService_CreateFxQuote_Handler,
+		},
+		{
+			MethodName: "SetFxRates",
+			Handler:    _MoneyMovementService_SetFxRates_Handler,
+		},
+		{
+			MethodName: "CreateWallet",
+			Handler:    _MoneyMovementService_CreateWallet_Handler,
+		},
+		{
+			MethodName: "GetWallet",
+			Handler:    _MoneyMovementService_GetWallet_Handler,
+		},
+		{
+			MethodName: "Deposit",
+			Handler:    _MoneyMovementService_Deposit_Handler,
+		},
+		{
+			MethodName: "RequestPayout",
+			Handler:    _MoneyMovementService_RequestPayout_Handler,
+		},
+		{
+			MethodName: "GetPayout",
+			Handler:    _MoneyMovementService_GetPayout_Handler,
+		},
+		{
+			MethodName: "Transfer",
+			Handler:    _MoneyMovementService_Transfer_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/money_movement_svc.proto",
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql" // Стандартный пакет для работы с SQL базами данных
 	"fmt"          // Пакет для форматированного ввода/вывода
 	_ "github.com/go-sql-driver/mysql"
@@ -8,8 +9,11 @@ import (
 	pb "github.com/sunr3d/gomicro/proto"                     // Протобаф сервис для gRPC
 	"google.golang.org/grpc"                                 // Библиотека для gRPC
 	"log"                                                    // Пакет логирования
-	"net"                                                    // Пакет для сетевых операций
+	"math"
+	"net" // Пакет для сетевых операций
 	"os"
+	"strconv"
+	"time"
 )
 
 // Константы подключения к БД (дефайны)
@@ -28,7 +32,8 @@ func main() {
 
 	/// БЛОК DataBase(!)
 	// Формирование строки подключения к БД (dsn = Data Source Name)
-	dsn := fmt.Sprintf("%s:%s@tcp(mysql-auth:3306)/%s", dbUser, dbPassword, dbName)
+	// parseTime=true нужен для чтения users.created_at в time.Time
+	dsn := fmt.Sprintf("%s:%s@tcp(mysql-auth:3306)/%s?parseTime=true", dbUser, dbPassword, dbName)
 
 	// Открытие соединения с базой данных
 	db, err = sql.Open(dbDriver, dsn)
@@ -55,7 +60,34 @@ func main() {
 	grpcServer := grpc.NewServer()
 
 	// Создание реализации сервиса аутентификации
-	authServerImplementation := auth.NewAuthImplementation(db)
+	// (параметры хеширования паролей и сроки действия токенов можно переопределить переменными окружения)
+	authServerImplementation, err := auth.NewAuthImplementation(db,
+		auth.HashParams{
+			Memory:  uint32(uintFromEnv("ARGON2_MEMORY_KIB", uint64(auth.DefaultHashParams.Memory), math.MaxUint32)),
+			Time:    uint32(uintFromEnv("ARGON2_TIME", uint64(auth.DefaultHashParams.Time), math.MaxUint32)),
+			Threads: uint8(uintFromEnv("ARGON2_THREADS", uint64(auth.DefaultHashParams.Threads), math.MaxUint8)),
+		},
+		durationFromEnv("ACCESS_TOKEN_TTL", auth.DefaultAccessTokenTTL),
+		durationFromEnv("REFRESH_TOKEN_TTL", auth.DefaultRefreshTokenTTL))
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// Хеширование паролей, оставшихся в БД открытым текстом
+	migrated, err := authServerImplementation.MigratePlaintextPasswords(context.Background())
+	if err != nil {
+		log.Fatalf("failed to migrate plaintext passwords: %v\n", err)
+	}
+	if migrated > 0 {
+		log.Printf("%d plaintext passwords hashed\n", migrated)
+	}
+
+	// Загрузка отозванных токенов и их периодическая синхронизация с другими репликами
+	if err := authServerImplementation.LoadRevocations(context.Background()); err != nil {
+		log.Fatalf("failed to load token revocations: %v\n", err)
+	}
+	go authServerImplementation.RunRevocationSync(context.Background(),
+		durationFromEnv("REVOCATION_SYNC_INTERVAL", auth.DefaultRevocationSyncInterval))
 
 	// Регистрация сервиса аутентификации в gRPC сервере из пакета "pb" (протобаф, находится в auth/proto/)
 	// (По сути привязываем сервер к БД)
@@ -76,3 +108,33 @@ func main() {
 	}
 	/// БЛОК gRPC SERVER(!)
 }
+
+// uintFromEnv читает положительное целое (не больше max) из переменной окружения name.
+// Если переменная не задана, возвращается значение по умолчанию def
+func uintFromEnv(name string, def uint64, max uint64) uint64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || n == 0 || n > max {
+		log.Fatalf("invalid %s %q: must be between 1 and %d\n", name, value, max)
+	}
+	return n
+}
+
+// durationFromEnv читает положительную длительность (например, "15m") из переменной окружения name.
+// Если переменная не задана, возвращается значение по умолчанию def
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < time.Second {
+		log.Fatalf("invalid %s %q: must be a duration of at least 1s\n", name, value)
+	}
+	return d
+}
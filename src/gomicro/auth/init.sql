@@ -14,15 +14,52 @@ USE auth;
 -- Создаем таблицу "user" с колонками:
     -- "id" (ключевая, при каждом новом добавлении происходит +1)
     -- "user_id" (логин в виде e-mail, уникальный, не может быть пустой)
-    -- "password" (пароль, не может быть пустой)
+    -- "password" (хеш пароля argon2id или bcrypt вместе с параметрами и солью, не может быть пустой;
+    --  открытые пароли из старых версий хешируются сервисом при запуске)
+    -- "created_at" (время регистрации)
 CREATE TABLE users (
     id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
     user_id VARCHAR(255) NOT NULL UNIQUE,
-    password VARCHAR(255) NOT NULL
+    password VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
 -- Добавляем первого юзера в таблицу "user" (id не добавляем, так как он автоматически инкрементируется)
-INSERT INTO users (user_id, password) VALUES ('sunr3d.coding@gmail.com', 'Test123');
+-- Пароль "Test123", хешированный argon2id
+INSERT INTO users (user_id, password) VALUES ('sunr3d.coding@gmail.com', '$argon2id$v=19$m=65536,t=3,p=2$PxJencZMYOIKevZQjpqxIQ$W7U0pu1rB6+JbFg1GQoTPSUzZ5cSW+xiy4Zwcg9IFls');
 
--- !!!В РЕАЛЬНОМ ПРОЕКТЕ ВСЕ ПАРОЛИ ДОЛЖНЫ БЫТЬ ХЕШИРОВАНЫ А НЕ ХРАНИТЬСЯ В ОТКРЫТОМ ВИДЕ!!!
+-- Создаем таблицу "refresh_token" с одноразовыми refresh токенами:
+    -- "token_hash" (SHA-256 токена в hex; сам токен не хранится)
+    -- "family_id" (цепочка токенов, начатая одним входом; при повторном
+    --  использовании токена отзывается вся цепочка)
+    -- "used_at" (время обмена на новый токен), "revoked_at" (время отзыва)
+CREATE TABLE refresh_token (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    family_id CHAR(32) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX (family_id),
+    INDEX (user_id)
+);
+
+-- Создаем таблицу "revoked_token" с jwt, отозванными до истечения срока (Logout):
+    -- "jti" (идентификатор токена), "expires_at" (срок действия токена;
+    --  после него запись больше не нужна и удаляется сервисом)
+CREATE TABLE revoked_token (
+    jti CHAR(32) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX (expires_at)
+);
 
+-- Создаем таблицу "user_revocation" с отзывами всех сессий пользователя:
+    -- jwt пользователя, выданные не позже "revoked_at", невалидны
+CREATE TABLE user_revocation (
+    user_id VARCHAR(255) NOT NULL PRIMARY KEY,
+    revoked_at TIMESTAMP(3) NOT NULL
+);
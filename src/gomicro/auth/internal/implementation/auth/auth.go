@@ -4,6 +4,7 @@ package auth
 import (
 	"context"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	jwt "github.com/golang-jwt/jwt/v5"
 	pb "github.com/sunr3d/gomicro/proto"
@@ -16,16 +17,56 @@ import (
 
 // Структура Implementation implements AuthServiceServer
 type Implementation struct {
-	db                                *sql.DB // Подключение к базе данных
-	pb.UnimplementedAuthServiceServer         // Заглушка для совместимости
+	db                                *sql.DB          // Подключение к базе данных
+	hashParams                        HashParams       // Параметры хеширования новых паролей
+	dummyHash                         string           // Хеш для проверки пароля несуществующего пользователя
+	accessTTL                         time.Duration    // Срок действия jwt
+	refreshTTL                        time.Duration    // Срок действия refresh токена
+	revocations                       *revocationStore // Отозванные токены
+	pb.UnimplementedAuthServiceServer                  // Заглушка для совместимости
 }
 
-// Конструктор (функция) для создания новой реализации сервиса (принимает БД, возвращает объект Implementation)
-func NewAuthImplementation(db *sql.DB) *Implementation {
-	return &Implementation{db: db}
+// Конструктор (функция) для создания новой реализации сервиса
+//
+// Параметры:
+//   - db: подключение к базе данных
+//   - hashParams: параметры argon2id для хеширования паролей
+//   - accessTTL: срок действия jwt
+//   - refreshTTL: срок действия refresh токена (продлевается при каждом обмене)
+//
+// Возвращает:
+//   - указатель на новый экземпляр Implementation
+//   - ошибку в случае неудачи
+func NewAuthImplementation(db *sql.DB, hashParams HashParams, accessTTL time.Duration, refreshTTL time.Duration) (*Implementation, error) {
+	if err := hashParams.validate(); err != nil {
+		return nil, err
+	}
+
+	// Вход несуществующего пользователя тоже вычисляет хеш, чтобы по времени ответа
+	// нельзя было определить, зарегистрирован ли логин
+	dummyHash, err := hashPassword("", hashParams)
+	if err != nil {
+		return nil, err
+	}
+	return &Implementation{
+		db:          db,
+		hashParams:  hashParams,
+		dummyHash:   dummyHash,
+		accessTTL:   accessTTL,
+		refreshTTL:  refreshTTL,
+		revocations: newRevocationStore(db),
+	}, nil
 }
 
 // Метод для получения токена с аутентификацией пользователя
+//
+// Кроме короткоживущего jwt выдается refresh токен, который обменивается
+// на новую пару методом RefreshToken.
+//
+// Пароль сверяется с хешем из БД на стороне сервиса за постоянное время.
+// Если хеш создан с устаревшими параметрами (или пароль хранится открытым текстом),
+// после успешной проверки он заменяется хешем с текущими параметрами.
+//
 // Сигнатура метода:
 // - ctx: контекст выполнения запроса
 // - credentials: структура с учетными данными пользователя
@@ -34,14 +75,14 @@ func (this *Implementation) GetToken(ctx context.Context, credentials *pb.Creden
 	// Локальная структура для хранения данных пользователя из БД
 	type user struct {
 		userID   string // Email пользователя
-		password string // Пароль пользователя
+		password string // Хеш пароля пользователя
 	}
 	// Создание экземпляра структуры для загрузки данных
 	var u user
 
 	// Подготовка SQL-запроса с безопасными плейсхолдерами.
-	// Ищем пользователя по логину и паролю
-	stmt, err := this.db.Prepare("SELECT user_id, password FROM users WHERE user_id = ? AND password = ?")
+	// Ищем пользователя по логину, пароль проверяется ниже
+	stmt, err := this.db.Prepare("SELECT user_id, password FROM users WHERE user_id = ?")
 
 	// Обработка ошибки подготовки запроса
 	if err != nil {
@@ -53,48 +94,145 @@ func (this *Implementation) GetToken(ctx context.Context, credentials *pb.Creden
 	defer stmt.Close()
 
 	// Выполнение запроса:
-	// - Подставляем логин и пароль из credentials
+	// - Подставляем логин из credentials
 	// - Загружаем результат в структуру пользователя
-	err = stmt.QueryRow(credentials.GetUserName(), credentials.GetPassword()).Scan(&u.userID, &u.password)
+	err = stmt.QueryRow(credentials.GetUserName()).Scan(&u.userID, &u.password)
 
 	// Обработка ошибок выполнения запроса
 	if err != nil {
 		// Если пользователь не найден
 		if errors.Is(err, sql.ErrNoRows) {
+			_, _, _ = verifyPassword(credentials.GetPassword(), this.dummyHash, this.hashParams)
 			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
 		}
 		// Для других ошибок - внутренняя ошибка сервера
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	// Генерация JWT токена для пользователя
-	jwtToken, err := createJWT(u.userID)
+	// Проверка пароля
+	ok, rehash, err := verifyPassword(credentials.GetPassword(), u.password, this.hashParams)
 	if err != nil {
-		return nil, err
+		log.Printf("user %s: %v\n", u.userID, err)
+		return nil, status.Error(codes.Internal, "password verification failed")
+	}
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
 	}
 
-	// Возврат токена в протобуферной структуре
-	return &pb.Token{Jwt: jwtToken}, nil
+	// Замена хеша с устаревшими параметрами. Ошибка не мешает входу: хеш будет заменен при следующем
+	if rehash {
+		if err := this.rehashPassword(u.userID, u.password, credentials.GetPassword()); err != nil {
+			log.Printf("user %s: rehash password: %v\n", u.userID, err)
+		}
+	}
+
+	// Генерация JWT и refresh токена для пользователя
+	return this.issueTokens(ctx, u.userID)
+}
+
+// rehashPassword заменяет сохраненное значение пароля old пользователя userID хешем
+// с текущими параметрами. Если пароль успел измениться, замена не выполняется
+func (this *Implementation) rehashPassword(userID string, old string, password string) error {
+	hash, err := hashPassword(password, this.hashParams)
+	if err != nil {
+		return err
+	}
+	_, err = this.db.Exec("UPDATE users SET password = ? WHERE user_id = ? AND password = ?", hash, userID, old)
+	return err
 }
 
-func createJWT(userID string) (string, error) {
+// MigratePlaintextPasswords хеширует пароли, сохраненные открытым текстом
+// (до появления хеширования). Повторный запуск не меняет уже хешированные пароли
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//
+// Возвращает:
+//   - количество хешированных паролей
+//   - ошибку в случае неудачи
+func (this *Implementation) MigratePlaintextPasswords(ctx context.Context) (int, error) {
+	rows, err := this.db.QueryContext(ctx, "SELECT user_id, password FROM users WHERE password NOT LIKE '$%'")
+	if err != nil {
+		return 0, err
+	}
+	type plaintext struct {
+		userID   string
+		password string
+	}
+	var pending []plaintext
+	for rows.Next() {
+		var p plaintext
+		if err := rows.Scan(&p.userID, &p.password); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, p := range pending {
+		if !isPlaintextPassword(p.password) {
+			continue
+		}
+		if err := this.rehashPassword(p.userID, p.password, p.password); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// tokenClaims - claims выдаваемых jwt. Стандартный iat хранит время выдачи в секундах,
+// поэтому для сравнения с отзывом сессий пользователя в токене есть и iat_ms
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	IssuedAtMs int64 `json:"iat_ms,omitempty"`
+}
+
+// issuedAtMs возвращает время выдачи токена (unix, мс). У токенов без iat_ms
+// (выданных до его появления) это начало секунды iat: отзыв в ту же секунду их задевает.
+// У токенов без iat - 0
+func (this *tokenClaims) issuedAtMs() int64 {
+	if this.IssuedAtMs != 0 {
+		return this.IssuedAtMs
+	}
+	if this.IssuedAt != nil {
+		return this.IssuedAt.Unix() * 1000
+	}
+	return 0
+}
+
+// createJWT выдает подписанный jwt пользователю userID со сроком действия ttl,
+// выданный в момент now
+func createJWT(userID string, ttl time.Duration, now time.Time) (string, error) {
 	// Получение ключа подписи из переменных окружения
 	key := []byte(os.Getenv("SIGNING_KEY"))
 
-	// Получение текущего времени
-	now := time.Now()
+	// Уникальный идентификатор токена, по которому его можно отозвать
+	jti, err := randomString(jtiLen, hex.EncodeToString)
+	if err != nil {
+		return "", status.Error(codes.Internal, err.Error())
+	}
 
 	// Создание нового JWT-токена с Claims:
 	// - iss: издатель токена
 	// - sub: идентификатор пользователя
 	// - iat: время создания токена
-	// - exp: время истечения токена (24 часа)
+	// - iat_ms: время создания токена в миллисекундах
+	// - exp: время истечения токена
+	// - jti: идентификатор токена
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
 		jwt.MapClaims{
-			"iss": "auth-service",                 // Издатель
-			"sub": userID,                         // ID пользователя
-			"iat": now.Unix(),                     // Время создания
-			"exp": now.Add(24 * time.Hour).Unix(), // Время истечения
+			"iss":    "auth-service",      // Издатель
+			"sub":    userID,              // ID пользователя
+			"iat":    now.Unix(),          // Время создания
+			"iat_ms": now.UnixMilli(),     // Время создания (мс)
+			"exp":    now.Add(ttl).Unix(), // Время истечения
+			"jti":    jti,                 // ID токена
 		})
 
 	// Подписание токена секретным ключом
@@ -111,6 +249,9 @@ func createJWT(userID string) (string, error) {
 // ValidateToken - метод объекта Implementation для валидации JWT токена
 // Назначение: проверить корректность предоставленного токена и вернуть информацию о пользователе
 //
+// Кроме подписи и срока действия проверяется, что токен не отозван (Logout, RevokeUserSessions).
+// Отзывы проверяются по кэшу в памяти, который синхронизируется с БД в RunRevocationSync
+//
 // Параметры:
 //   - ctx: контекст выполнения запроса (может содержать тайм-аут, трассировку и т.д.)
 //   - token: протобуферная структура, содержащая JWT токен
@@ -119,24 +260,34 @@ func createJWT(userID string) (string, error) {
 //   - *pb.User: структура пользователя с идентификатором при успешной валидации
 //   - error: ошибка в случае невалидного токена или проблем с аутентификацией
 func (this *Implementation) ValidateToken(ctx context.Context, token *pb.Token) (*pb.User, error) {
-	// Извлечение ключа подписи из переменных окружения
-	// SIGNING_KEY - секретный ключ, используемый для проверки подписи токена
-	// Преобразование строки в байтовый массив для криптографических операций
-	key := []byte(os.Getenv("SIGNING_KEY"))
-
-	// Вызов функции валидации JWT с переданным токеном и ключом подписи
-	// Функция проверяет целостность, срок действия и подпись токена
-	// Возвращает идентификатор пользователя при успешной проверке
-	userID, err := validateJWT(token.Jwt, key)
+	// Проверка целостности, срока действия, подписи и отзыва токена
+	// Возвращает Claims токена при успешной проверке
+	claims, err := this.authenticate(token.Jwt)
 	if err != nil {
-		// В случае ошибки валидации (просроченный или некорректный токен)
+		// В случае ошибки валидации (просроченный, отозванный или некорректный токен)
 		// возвращаем nil и ошибку для дальнейшей обработки на стороне клиента
 		return nil, err
 	}
 
 	// Создание и возврат протобуферной структуры пользователя
 	// с идентификатором, извлеченным из валидного токена
-	return &pb.User{UserID: userID}, nil
+	return &pb.User{UserID: claims.Subject}, nil
+}
+
+// authenticate проверяет jwt ключом подписи SIGNING_KEY и отсутствие отзыва
+//
+// Возвращает:
+//   - Claims токена при успешной проверке
+//   - ошибку Unauthenticated, если токен невалиден или отозван
+func (this *Implementation) authenticate(t string) (*tokenClaims, error) {
+	claims, err := validateJWT(t, []byte(os.Getenv("SIGNING_KEY")))
+	if err != nil {
+		return nil, err
+	}
+	if this.revocations.isRevoked(claims) {
+		return nil, status.Error(codes.Unauthenticated, "token revoked, sign in again")
+	}
+	return claims, nil
 }
 
 // validateJWT выполняет валидацию и проверку JWT токена
@@ -145,22 +296,15 @@ func (this *Implementation) ValidateToken(ctx context.Context, token *pb.Token)
 //   - signingKey: ключ для проверки подписи токена
 //
 // Возвращает:
-//   - ID пользователя (Subject) при успешной валидации
+//   - Claims токена (ID пользователя в Subject) при успешной валидации
 //   - Ошибку в случае невалидного токена
-func validateJWT(t string, signingKey []byte) (string, error) {
-	// Пользовательская структура Claims для парсинга токена
-	// Расширяет стандартные RegisteredClaims библиотеки jwt
-	// Позволяет работать со стандартными полями токена (exp, iat, sub и др.)
-	type MyClaims struct {
-		jwt.RegisteredClaims
-	}
-
-	// Парсинг и проверка токена с использованием пользовательских Claims
+func validateJWT(t string, signingKey []byte) (*tokenClaims, error) {
+	// Парсинг и проверка токена с использованием claims сервиса tokenClaims
 	// jwt.ParseWithClaims выполняет полную валидацию:
 	// - Проверка подписи
 	// - Декодирование Claims
 	// - Проверка целостности токена
-	parsedToken, err := jwt.ParseWithClaims(t, &MyClaims{}, func(token *jwt.Token) (interface{}, error) {
+	parsedToken, err := jwt.ParseWithClaims(t, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Анонимная функция возвращает ключ для проверки подписи токена
 		// Используется криптографический ключ, которым был подписан токен
 		return signingKey, nil
@@ -172,24 +316,24 @@ func validateJWT(t string, signingKey []byte) (string, error) {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			// Возврат ошибки с кодом "не аутентифицирован"
 			// и информативным сообщением о необходимости получения нового токена
-			return "", status.Error(codes.Unauthenticated, "token expired, get new token")
+			return nil, status.Error(codes.Unauthenticated, "token expired, get new token")
 		} else {
 			// Для других ошибок (неверная подпись, некорректный формат) -
 			// общая ошибка аутентификации
-			return "", status.Error(codes.Unauthenticated, "unauthenticated")
+			return nil, status.Error(codes.Unauthenticated, "unauthenticated")
 		}
 	}
 
-	// Безопасное преобразование Claims к пользовательскому типу MyClaims
+	// Безопасное преобразование Claims к типу tokenClaims
 	// Проверка, что распарсенные Claims имеют корректный тип
-	claims, ok := parsedToken.Claims.(*MyClaims)
+	claims, ok := parsedToken.Claims.(*tokenClaims)
 	if !ok {
 		// Если преобразование не удалось - возврат внутренней ошибки сервера
 		// Может означать несоответствие структуры Claims
-		return "", status.Error(codes.Internal, "claims type assertion failed")
+		return nil, status.Error(codes.Internal, "claims type assertion failed")
 	}
 
-	// Извлечение и возврат ID пользователя (Subject) из Claims
-	// Subject обычно содержит уникальный идентификатор пользователя
-	return claims.RegisteredClaims.Subject, nil
+	// Возврат Claims: Subject содержит идентификатор пользователя,
+	// ID (jti) и время выдачи нужны для проверки отзыва
+	return claims, nil
 }
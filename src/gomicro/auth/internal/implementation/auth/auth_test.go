@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	_ "github.com/go-sql-driver/mysql"
+	pb "github.com/sunr3d/gomicro/proto"
+	"os"
+	"testing"
+	"time"
+)
+
+// Дешевые параметры argon2id, чтобы тесты не тратили 64 МиБ на каждый хеш
+var testHashParams = HashParams{Memory: 64, Time: 1, Threads: 1}
+
+// Тесты с БД работают с настоящей MySQL, инициализированной скриптом init.sql.
+// Строка подключения задается переменной окружения AUTH_TEST_DSN, например:
+//
+//	auth_user:Auth123@tcp(localhost:3306)/auth?parseTime=true
+//
+// Без нее тесты пропускаются.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("AUTH_TEST_DSN")
+	if dsn == "" {
+		t.Skip("AUTH_TEST_DSN is not set")
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Log(err)
+		}
+	})
+
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newTestImplementation создает сервис с ключом подписи jwt для тестов.
+// db может быть nil, если тест не обращается к БД
+func newTestImplementation(t *testing.T, db *sql.DB) *Implementation {
+	t.Helper()
+
+	t.Setenv("SIGNING_KEY", "test-signing-key")
+	impl, err := NewAuthImplementation(db, testHashParams, time.Minute, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return impl
+}
+
+// createTestUser регистрирует пользователя с уникальным email и паролем password
+func createTestUser(t *testing.T, impl *Implementation, password string) string {
+	t.Helper()
+
+	suffix, err := randomString(8, hex.EncodeToString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := impl.Register(context.Background(), &pb.RegisterPayload{UserName: "test-" + suffix + "@example.com", Password: password})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user.GetUserID()
+}
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// HashParams - параметры argon2id для новых хешей паролей
+//
+// Параметры сохраняются в самом хеше (формат PHC: $argon2id$v=19$m=...,t=...,p=...$соль$хеш),
+// поэтому у каждого пользователя могут быть свои. При входе хеш со старыми
+// параметрами (или bcrypt, или открытый пароль) заменяется хешем с текущими.
+type HashParams struct {
+	Memory  uint32 // Память в КиБ
+	Time    uint32 // Число проходов
+	Threads uint8  // Степень параллелизма
+}
+
+// DefaultHashParams - параметры argon2id по умолчанию (рекомендация OWASP: 64 МиБ, 3 прохода)
+var DefaultHashParams = HashParams{Memory: 64 * 1024, Time: 3, Threads: 2}
+
+const (
+	argon2SaltLen = 16 // Длина соли в байтах
+	argon2KeyLen  = 32 // Длина хеша в байтах
+)
+
+// Границы параметров argon2id. Параметры вне них argon2 не принимает (паникует)
+// или считает слишком долго: такой хеш в БД считается поврежденным, а такая настройка - ошибкой
+const (
+	maxArgon2Memory = 1024 * 1024 // 1 ГиБ в КиБ
+	maxArgon2Time   = 16
+)
+
+// Политика паролей для новых и измененных паролей (на вход со старыми паролями не влияет)
+const (
+	minPasswordLen = 8   // Минимальная длина в символах
+	maxPasswordLen = 128 // Ограничивает время хеширования для слишком длинных паролей
+)
+
+var errMalformedHash = errors.New("malformed password hash")
+
+// validate проверяет, что параметры в допустимых границах
+func (this HashParams) validate() error {
+	if this.Time < 1 || this.Time > maxArgon2Time {
+		return fmt.Errorf("argon2 time must be 1 to %d", maxArgon2Time)
+	}
+	if this.Threads < 1 {
+		return errors.New("argon2 threads must be at least 1")
+	}
+	if this.Memory < 8*uint32(this.Threads) || this.Memory > maxArgon2Memory {
+		return fmt.Errorf("argon2 memory must be %d to %d KiB", 8*uint32(this.Threads), maxArgon2Memory)
+	}
+	return nil
+}
+
+// hashPassword вычисляет хеш пароля argon2id со случайной солью
+//
+// Возвращает:
+//   - хеш в формате PHC
+//   - ошибку в случае неудачи
+func hashPassword(password string, params HashParams) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, argon2KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		params.Memory,
+		params.Time,
+		params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// verifyPassword сверяет пароль с сохраненным значением за постоянное время
+//
+// Поддерживаются хеши argon2id, bcrypt ($2a$, $2b$, $2y$) и открытые пароли,
+// сохраненные до появления хеширования (значение не начинается с "$").
+//
+// Возвращает:
+//   - true, если пароль верный
+//   - true, если сохраненное значение нужно заменить хешем с параметрами params
+//   - ошибку, если сохраненный хеш не разбирается
+func verifyPassword(password string, stored string, params HashParams) (bool, bool, error) {
+	switch {
+	case strings.HasPrefix(stored, "$argon2id$"):
+		hashParams, salt, key, err := decodeArgon2Hash(stored)
+		if err != nil {
+			return false, false, err
+		}
+		candidate := argon2.IDKey([]byte(password), salt, hashParams.Time, hashParams.Memory, hashParams.Threads, uint32(len(key)))
+		ok := subtle.ConstantTimeCompare(candidate, key) == 1
+		return ok, ok && hashParams != params, nil
+
+	case strings.HasPrefix(stored, "$2"):
+		err := bcrypt.CompareHashAndPassword([]byte(stored), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, err
+		}
+		return true, true, nil
+
+	case strings.HasPrefix(stored, "$"):
+		return false, false, errMalformedHash
+
+	default:
+		ok := subtle.ConstantTimeCompare([]byte(password), []byte(stored)) == 1
+		return ok, ok, nil
+	}
+}
+
+// decodeArgon2Hash разбирает хеш argon2id в формате PHC
+func decodeArgon2Hash(stored string) (HashParams, []byte, []byte, error) {
+	var params HashParams
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", соль, хеш
+	parts := strings.Split(stored, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errMalformedHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return params, nil, nil, errMalformedHash
+	}
+	if params.validate() != nil {
+		return params, nil, nil, errMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errMalformedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errMalformedHash
+	}
+	return params, salt, key, nil
+}
+
+// validatePassword проверяет пароль на соответствие политике паролей:
+// от minPasswordLen до maxPasswordLen символов, хотя бы одна буква и одна цифра,
+// и пароль не совпадает с логином
+//
+// Возвращает:
+//   - ошибку InvalidArgument с описанием нарушенного правила
+func validatePassword(password string, userID string) error {
+	length := utf8.RuneCountInString(password)
+	if length < minPasswordLen || length > maxPasswordLen {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("password must be %d to %d characters long", minPasswordLen, maxPasswordLen))
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return status.Error(codes.InvalidArgument, "password must contain at least one letter and one digit")
+	}
+
+	if strings.EqualFold(password, userID) {
+		return status.Error(codes.InvalidArgument, "password must not match the email")
+	}
+	return nil
+}
+
+// isPlaintextPassword сообщает, что значение колонки password - открытый пароль,
+// сохраненный до появления хеширования
+func isPlaintextPassword(stored string) bool {
+	return !strings.HasPrefix(stored, "$")
+}
@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"context"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHashPasswordRoundTrip(t *testing.T) {
+	hash, err := hashPassword("Secret123", testHashParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("unexpected hash format %q", hash)
+	}
+
+	other, err := hashPassword("Secret123", testHashParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == hash {
+		t.Error("two hashes of the same password are equal, salt is not random")
+	}
+
+	params, _, _, err := decodeArgon2Hash(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params != testHashParams {
+		t.Errorf("decoded params = %+v, want %+v", params, testHashParams)
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	argon2Hash, err := hashPassword("Secret123", testHashParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldParams := HashParams{Memory: 32, Time: 2, Threads: 1}
+	oldArgon2Hash, err := hashPassword("Secret123", oldParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("Secret123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		password   string
+		stored     string
+		wantOK     bool
+		wantRehash bool
+	}{
+		{name: "argon2id", password: "Secret123", stored: argon2Hash, wantOK: true},
+		{name: "argon2id wrong password", password: "Secret124", stored: argon2Hash},
+		{name: "argon2id old params", password: "Secret123", stored: oldArgon2Hash, wantOK: true, wantRehash: true},
+		{name: "argon2id old params wrong password", password: "Secret124", stored: oldArgon2Hash},
+		{name: "bcrypt", password: "Secret123", stored: string(bcryptHash), wantOK: true, wantRehash: true},
+		{name: "bcrypt wrong password", password: "Secret124", stored: string(bcryptHash)},
+		{name: "plaintext", password: "Test123", stored: "Test123", wantOK: true, wantRehash: true},
+		{name: "plaintext wrong password", password: "Test124", stored: "Test123"},
+		{name: "plaintext prefix", password: "Test12", stored: "Test123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, rehash, err := verifyPassword(tt.password, tt.stored, testHashParams)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.wantOK || rehash != tt.wantRehash {
+				t.Errorf("verifyPassword = (%v, %v), want (%v, %v)", ok, rehash, tt.wantOK, tt.wantRehash)
+			}
+		})
+	}
+}
+
+func TestVerifyPasswordMalformedHash(t *testing.T) {
+	hash, err := hashPassword("Secret123", testHashParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(hash, "$")
+	salt, key := parts[4], parts[5]
+	withParams := func(params string) string {
+		return "$argon2id$v=19$" + params + "$" + salt + "$" + key
+	}
+
+	tests := []struct {
+		name   string
+		stored string
+	}{
+		{name: "prefix only", stored: "$argon2id$"},
+		{name: "missing key", stored: "$argon2id$v=19$m=64,t=1,p=1$" + salt},
+		{name: "extra part", stored: hash + "$" + key},
+		{name: "wrong version", stored: "$argon2id$v=16$m=64,t=1,p=1$" + salt + "$" + key},
+		{name: "non-numeric params", stored: withParams("m=x,t=1,p=1")},
+		{name: "zero time", stored: withParams("m=64,t=0,p=1")},
+		{name: "zero threads", stored: withParams("m=64,t=1,p=0")},
+		{name: "threads overflow", stored: withParams("m=64,t=1,p=256")},
+		{name: "memory below threads minimum", stored: withParams("m=7,t=1,p=1")},
+		{name: "memory too large", stored: withParams("m=4294967295,t=1,p=1")},
+		{name: "time too large", stored: withParams("m=64,t=4294967295,p=1")},
+		{name: "invalid salt", stored: "$argon2id$v=19$m=64,t=1,p=1$!!!$" + key},
+		{name: "empty key", stored: "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$"},
+		{name: "unknown algorithm", stored: "$scrypt$ln=15,r=8,p=1$" + salt + "$" + key},
+		{name: "truncated bcrypt", stored: "$2a$10$short"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, rehash, err := verifyPassword("Secret123", tt.stored, testHashParams)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if ok || rehash {
+				t.Errorf("verifyPassword = (%v, %v), want (false, false)", ok, rehash)
+			}
+		})
+	}
+}
+
+func TestNewAuthImplementationHashParams(t *testing.T) {
+	for _, params := range []HashParams{
+		{Memory: 64, Time: 0, Threads: 1},
+		{Memory: 64, Time: 1, Threads: 0},
+		{Memory: 64, Time: maxArgon2Time + 1, Threads: 1},
+		{Memory: maxArgon2Memory + 1, Time: 1, Threads: 1},
+	} {
+		if _, err := NewAuthImplementation(nil, params, time.Minute, time.Hour); err == nil {
+			t.Errorf("params %+v: expected error", params)
+		}
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "valid", password: "Secret123"},
+		{name: "minimum length", password: "abcdefg1"},
+		{name: "maximum length", password: strings.Repeat("a", maxPasswordLen-1) + "1"},
+		{name: "non-ascii letters", password: "пароль123"},
+		{name: "too short", password: "abcdef1", wantErr: true},
+		{name: "too long", password: strings.Repeat("a", maxPasswordLen) + "1", wantErr: true},
+		{name: "empty", password: "", wantErr: true},
+		{name: "missing letter", password: "12345678", wantErr: true},
+		{name: "missing digit", password: "abcdefgh", wantErr: true},
+		{name: "matches email", password: "User1@Example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePassword(tt.password, "user1@example.com")
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMigratePlaintextPasswords(t *testing.T) {
+	db := openTestDB(t)
+	impl := newTestImplementation(t, db)
+	userID := createTestUser(t, impl, "Secret123")
+
+	// Пароль из версии без хеширования
+	if _, err := db.Exec("UPDATE users SET password = ? WHERE user_id = ?", "Plain123", userID); err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, err := impl.MigratePlaintextPasswords(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated < 1 {
+		t.Errorf("migrated = %d, want at least 1", migrated)
+	}
+
+	var stored string
+	if err = db.QueryRow("SELECT password FROM users WHERE user_id = ?", userID).Scan(&stored); err != nil {
+		t.Fatal(err)
+	}
+	ok, rehash, err := verifyPassword("Plain123", stored, testHashParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || rehash {
+		t.Errorf("migrated password: verifyPassword = (%v, %v), want (true, false)", ok, rehash)
+	}
+
+	// Повторный запуск не трогает уже хешированный пароль
+	if _, err = impl.MigratePlaintextPasswords(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var again string
+	if err = db.QueryRow("SELECT password FROM users WHERE user_id = ?", userID).Scan(&again); err != nil {
+		t.Fatal(err)
+	}
+	if again != stored {
+		t.Error("second migration rehashed an already hashed password")
+	}
+}
@@ -0,0 +1,263 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	insertRevokedTokenQuery        = "INSERT IGNORE INTO revoked_token (jti, user_id, expires_at) VALUES (?, ?, FROM_UNIXTIME(?))"
+	selectRevokedTokensQuery       = "SELECT jti, UNIX_TIMESTAMP(expires_at) FROM revoked_token WHERE expires_at > NOW()"
+	deleteExpiredRevokedTokenQuery = "DELETE FROM revoked_token WHERE expires_at <= NOW()"
+	upsertUserRevocationQuery      = "INSERT INTO user_revocation (user_id, revoked_at) VALUES (?, FROM_UNIXTIME(? / 1000)) ON DUPLICATE KEY UPDATE revoked_at = GREATEST(revoked_at, VALUES(revoked_at))"
+	selectUserRevocationsQuery     = "SELECT user_id, CAST(UNIX_TIMESTAMP(revoked_at) * 1000 AS SIGNED) FROM user_revocation"
+	jtiLen                         = 16 // Длина идентификатора jwt в байтах (до кодирования hex)
+)
+
+// DefaultRevocationSyncInterval - интервал синхронизации кэша отзывов с БД по умолчанию
+const DefaultRevocationSyncInterval = 5 * time.Second
+
+// revocationStore - отозванные токены: в БД (общие для всех реплик сервиса) и в кэше в памяти
+//
+// ValidateToken проверяет только кэш, чтобы не обращаться к БД на каждый запрос.
+// Отзыв на этой реплике попадает в кэш сразу, на остальных - при следующей синхронизации,
+// поэтому отозванный токен может приниматься другими репликами до одного интервала синхронизации.
+// Отзыв не отменяется, поэтому синхронизация только добавляет записи и удаляет истекшие.
+type revocationStore struct {
+	db     *sql.DB
+	mu     sync.RWMutex
+	tokens map[string]int64 // jti -> exp токена (unix); после exp токен и так невалиден
+	users  map[string]int64 // user_id -> время отзыва (unix, мс); невалидны токены, выданные не позже
+}
+
+func newRevocationStore(db *sql.DB) *revocationStore {
+	return &revocationStore{db: db, tokens: map[string]int64{}, users: map[string]int64{}}
+}
+
+// isRevoked сообщает, что токен отозван по jti или всеми сессиями пользователя.
+// Токены без jti (выданные до появления отзыва) отзываются только вместе с сессиями пользователя
+func (this *revocationStore) isRevoked(claims *tokenClaims) bool {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+
+	if _, ok := this.tokens[claims.ID]; ok && claims.ID != "" {
+		return true
+	}
+	revokedAt, ok := this.users[claims.Subject]
+	return ok && claims.issuedAtMs() <= revokedAt
+}
+
+// issueTime возвращает время выдачи нового токена пользователю userID: текущее,
+// но строго позже известного этой реплике отзыва его сессий. Иначе вход в ту же
+// миллисекунду, что и отзыв, получил бы уже отозванный токен
+func (this *revocationStore) issueTime(userID string) time.Time {
+	now := time.Now()
+
+	this.mu.RLock()
+	revokedAt, ok := this.users[userID]
+	this.mu.RUnlock()
+
+	if ok && now.UnixMilli() <= revokedAt {
+		return time.UnixMilli(revokedAt + 1)
+	}
+	return now
+}
+
+// revokeToken отзывает токен jti пользователя userID до его истечения expiresAt (unix)
+func (this *revocationStore) revokeToken(ctx context.Context, jti string, userID string, expiresAt int64) error {
+	_, err := this.db.ExecContext(ctx, insertRevokedTokenQuery, jti, userID, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	this.mu.Lock()
+	this.tokens[jti] = expiresAt
+	this.mu.Unlock()
+	return nil
+}
+
+// revokeUser отзывает все токены пользователя userID, выданные до текущей миллисекунды включительно
+func (this *revocationStore) revokeUser(ctx context.Context, userID string) error {
+	revokedAt, err := insertUserRevocation(ctx, this.db, userID)
+	if err != nil {
+		return err
+	}
+	this.cacheUserRevocation(userID, revokedAt)
+	return nil
+}
+
+// insertUserRevocation записывает в db отзыв всех токенов пользователя userID, выданных
+// до текущей миллисекунды включительно. В кэш отзыв попадает через cacheUserRevocation
+// (после фиксации транзакции, если db - транзакция)
+//
+// Возвращает:
+//   - время отзыва (unix, мс)
+//   - ошибку в случае неудачи
+func insertUserRevocation(ctx context.Context, db execer, userID string) (int64, error) {
+	revokedAt := time.Now().UnixMilli()
+	_, err := db.ExecContext(ctx, upsertUserRevocationQuery, userID, revokedAt)
+	return revokedAt, err
+}
+
+// cacheUserRevocation добавляет в кэш отзыв токенов пользователя userID, выданных не позже revokedAt
+func (this *revocationStore) cacheUserRevocation(userID string, revokedAt int64) {
+	this.mu.Lock()
+	this.users[userID] = max(this.users[userID], revokedAt)
+	this.mu.Unlock()
+}
+
+// sync загружает отзывы из БД в кэш и удаляет истекшие отзывы из БД и кэша
+func (this *revocationStore) sync(ctx context.Context) error {
+	if _, err := this.db.ExecContext(ctx, deleteExpiredRevokedTokenQuery); err != nil {
+		return err
+	}
+
+	tokens := map[string]int64{}
+	err := this.scan(ctx, selectRevokedTokensQuery, tokens)
+	if err != nil {
+		return err
+	}
+	users := map[string]int64{}
+	err = this.scan(ctx, selectUserRevocationsQuery, users)
+	if err != nil {
+		return err
+	}
+
+	// Отзывы, сделанные на этой реплике во время загрузки, могли не попасть в выборку
+	now := time.Now().Unix()
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	for jti, expiresAt := range this.tokens {
+		if expiresAt > now {
+			tokens[jti] = expiresAt
+		}
+	}
+	for userID, revokedAt := range this.users {
+		users[userID] = max(users[userID], revokedAt)
+	}
+	this.tokens = tokens
+	this.users = users
+	return nil
+}
+
+// scan читает пары (ключ, unix время в секундах или мс - как в query) результата query в dst
+func (this *revocationStore) scan(ctx context.Context, query string, dst map[string]int64) error {
+	rows, err := this.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key  string
+			unix int64
+		)
+		if err := rows.Scan(&key, &unix); err != nil {
+			return err
+		}
+		dst[key] = unix
+	}
+	return rows.Err()
+}
+
+// LoadRevocations загружает отозванные токены из БД в кэш.
+// Вызывается при запуске, до приема запросов
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//
+// Возвращает:
+//   - ошибку в случае неудачи
+func (this *Implementation) LoadRevocations(ctx context.Context) error {
+	return this.revocations.sync(ctx)
+}
+
+// RunRevocationSync периодически синхронизирует кэш отозванных токенов с БД,
+// чтобы отзывы, сделанные на других репликах, применялись и на этой.
+// Блокирует вызывающую горутину до отмены ctx.
+//
+// Параметры:
+//   - ctx: контекст, отмена которого останавливает синхронизацию
+//   - interval: интервал между синхронизациями
+func (this *Implementation) RunRevocationSync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := this.revocations.sync(ctx); err != nil {
+				log.Printf("revocation sync: %v\n", err)
+			}
+		}
+	}
+}
+
+// Logout отзывает jwt и, если передан refresh токен, всю цепочку refresh токенов этого входа
+//
+// Параметры:
+//   - ctx: контекст выполнения запроса
+//   - payload: jwt и refresh токен
+//
+// Возвращает:
+//   - пустой ответ при успешном выходе
+//   - ошибку Unauthenticated (jwt невалиден или уже отозван) или Internal
+func (this *Implementation) Logout(ctx context.Context, payload *pb.LogoutPayload) (*emptypb.Empty, error) {
+	claims, err := this.authenticate(payload.GetJwt())
+	if err != nil {
+		return nil, err
+	}
+
+	// Токены без jti отозвать по отдельности нельзя, они истекут сами
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		err = this.revocations.revokeToken(ctx, claims.ID, claims.Subject, claims.ExpiresAt.Unix())
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	if payload.GetRefreshToken() != "" {
+		err = this.revokeRefreshFamily(ctx, payload.GetRefreshToken(), claims.Subject)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// RevokeUserSessions отзывает все jwt и refresh токены пользователя.
+// Права администратора проверяет вызывающая сторона (api_gateway)
+//
+// Параметры:
+//   - ctx: контекст выполнения запроса
+//   - payload: идентификатор пользователя
+//
+// Возвращает:
+//   - пустой ответ при успешном отзыве
+//   - ошибку InvalidArgument или Internal
+func (this *Implementation) RevokeUserSessions(ctx context.Context, payload *pb.RevokeUserSessionsPayload) (*emptypb.Empty, error) {
+	if payload.GetUserID() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id is required")
+	}
+
+	if err := this.revocations.revokeUser(ctx, payload.GetUserID()); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err := revokeUserRefreshTokens(ctx, this.db, payload.GetUserID()); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	log.Printf("user %s: all sessions revoked\n", payload.GetUserID())
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"context"
+	jwt "github.com/golang-jwt/jwt/v5"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestIsRevoked(t *testing.T) {
+	now := time.Now()
+	store := newRevocationStore(nil)
+	store.tokens["revoked-jti"] = now.Add(time.Minute).Unix()
+	store.users["cutoff@example.com"] = now.UnixMilli()
+
+	issued := func(id, subject string, at time.Time) tokenClaims {
+		return tokenClaims{
+			RegisteredClaims: jwt.RegisteredClaims{ID: id, Subject: subject, IssuedAt: jwt.NewNumericDate(at)},
+			IssuedAtMs:       at.UnixMilli(),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		claims tokenClaims
+		want   bool
+	}{
+		{
+			name:   "active token",
+			claims: issued("active-jti", "user@example.com", now),
+		},
+		{
+			name:   "revoked jti",
+			claims: issued("revoked-jti", "user@example.com", now),
+			want:   true,
+		},
+		{
+			name:   "no jti",
+			claims: issued("", "user@example.com", now),
+		},
+		{
+			name:   "issued before cutoff",
+			claims: issued("active-jti", "cutoff@example.com", now.Add(-time.Minute)),
+			want:   true,
+		},
+		{
+			name:   "issued in cutoff millisecond",
+			claims: issued("active-jti", "cutoff@example.com", now),
+			want:   true,
+		},
+		{
+			name:   "issued millisecond after cutoff",
+			claims: issued("active-jti", "cutoff@example.com", now.Add(time.Millisecond)),
+		},
+		{
+			name:   "no iat_ms, iat in cutoff second",
+			claims: tokenClaims{RegisteredClaims: jwt.RegisteredClaims{ID: "active-jti", Subject: "cutoff@example.com", IssuedAt: jwt.NewNumericDate(now)}},
+			want:   true,
+		},
+		{
+			name:   "no iat_ms, iat after cutoff second",
+			claims: tokenClaims{RegisteredClaims: jwt.RegisteredClaims{ID: "active-jti", Subject: "cutoff@example.com", IssuedAt: jwt.NewNumericDate(now.Add(time.Second))}},
+		},
+		{
+			name:   "no iat with cutoff",
+			claims: tokenClaims{RegisteredClaims: jwt.RegisteredClaims{ID: "active-jti", Subject: "cutoff@example.com"}},
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := store.isRevoked(&tt.claims); got != tt.want {
+				t.Errorf("isRevoked = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateRevokedToken(t *testing.T) {
+	impl := newTestImplementation(t, nil)
+	token, err := createJWT("user@example.com", time.Minute, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := impl.authenticate(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Отзыв всех сессий миллисекундой раньше выдачи не задевает токен
+	impl.revocations.users[claims.Subject] = claims.IssuedAtMs - 1
+	if _, err = impl.authenticate(token); err != nil {
+		t.Fatalf("token issued after cutoff: %v", err)
+	}
+
+	impl.revocations.users[claims.Subject] = claims.IssuedAtMs
+	if _, err = impl.authenticate(token); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("token issued before cutoff: expected Unauthenticated, got %v", err)
+	}
+
+	// Без отзыва сессий токен отклоняется по отозванному jti
+	delete(impl.revocations.users, claims.Subject)
+	impl.revocations.tokens[claims.ID] = claims.ExpiresAt.Unix()
+	if _, err = impl.authenticate(token); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("revoked jti: expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestTokenIssuedRightAfterRevocation(t *testing.T) {
+	impl := newTestImplementation(t, nil)
+	userID := "user@example.com"
+
+	// Отзыв с временем чуть впереди часов реплики: новый токен все равно выдается после него
+	revokedAt := time.Now().Add(50 * time.Millisecond).UnixMilli()
+	impl.revocations.cacheUserRevocation(userID, revokedAt)
+
+	issuedAt := impl.revocations.issueTime(userID)
+	if issuedAt.UnixMilli() <= revokedAt {
+		t.Fatalf("issueTime = %d ms, want after %d ms", issuedAt.UnixMilli(), revokedAt)
+	}
+	token, err := createJWT(userID, time.Minute, issuedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = impl.authenticate(token); err != nil {
+		t.Fatalf("token issued right after revocation: %v", err)
+	}
+}
+
+func TestLogoutRevokesToken(t *testing.T) {
+	db := openTestDB(t)
+	impl := newTestImplementation(t, db)
+	userID := createTestUser(t, impl, "Secret123")
+	ctx := context.Background()
+
+	token, err := impl.GetToken(ctx, &pb.Credentials{UserName: userID, Password: "Secret123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := impl.GetToken(ctx, &pb.Credentials{UserName: userID, Password: "Secret123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = impl.Logout(ctx, &pb.LogoutPayload{Jwt: token.GetJwt(), RefreshToken: token.GetRefreshToken()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = impl.ValidateToken(ctx, &pb.Token{Jwt: token.GetJwt()}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("logged out jwt: expected Unauthenticated, got %v", err)
+	}
+	_, err = impl.RefreshToken(ctx, &pb.RefreshTokenPayload{RefreshToken: token.GetRefreshToken()})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("logged out refresh token: expected Unauthenticated, got %v", err)
+	}
+
+	// Выход затрагивает только свой вход
+	if _, err = impl.ValidateToken(ctx, &pb.Token{Jwt: other.GetJwt()}); err != nil {
+		t.Fatalf("jwt of another sign-in: %v", err)
+	}
+}
+
+func TestRevocationSync(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	// Две реплики сервиса с общей БД
+	replica := newTestImplementation(t, db)
+	other := newTestImplementation(t, db)
+	userID := createTestUser(t, replica, "Secret123")
+
+	loggedOut, err := replica.GetToken(ctx, &pb.Credentials{UserName: userID, Password: "Secret123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = replica.Logout(ctx, &pb.LogoutPayload{Jwt: loggedOut.GetJwt()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// До синхронизации другая реплика еще принимает токен
+	if _, err = other.ValidateToken(ctx, &pb.Token{Jwt: loggedOut.GetJwt()}); err != nil {
+		t.Fatalf("before sync: %v", err)
+	}
+	if err = other.LoadRevocations(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = other.ValidateToken(ctx, &pb.Token{Jwt: loggedOut.GetJwt()}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("after sync: expected Unauthenticated, got %v", err)
+	}
+
+	// Отзыв всех сессий пользователя тоже доходит до другой реплики
+	active, err := replica.GetToken(ctx, &pb.Credentials{UserName: userID, Password: "Secret123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = replica.RevokeUserSessions(ctx, &pb.RevokeUserSessionsPayload{UserID: userID}); err != nil {
+		t.Fatal(err)
+	}
+	if err = other.LoadRevocations(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = other.ValidateToken(ctx, &pb.Token{Jwt: active.GetJwt()}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("after user revocation sync: expected Unauthenticated, got %v", err)
+	}
+}
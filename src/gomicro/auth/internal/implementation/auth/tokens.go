@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+	"time"
+)
+
+// Сроки действия токенов по умолчанию
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute    // jwt
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour // Каждого refresh токена в цепочке
+)
+
+const (
+	insertRefreshTokenQuery        = "INSERT INTO refresh_token (token_hash, family_id, user_id, expires_at) VALUES (?, ?, ?, NOW() + INTERVAL ? SECOND)"
+	selectRefreshTokenQuery        = "SELECT family_id, user_id, used_at IS NOT NULL, revoked_at IS NOT NULL, expires_at <= NOW() FROM refresh_token WHERE token_hash = ? FOR UPDATE"
+	markRefreshTokenUsedQuery      = "UPDATE refresh_token SET used_at = NOW() WHERE token_hash = ?"
+	revokeRefreshFamilyQuery       = "UPDATE refresh_token SET revoked_at = NOW() WHERE family_id = ? AND revoked_at IS NULL"
+	revokeUserRefreshTokensQuery   = "UPDATE refresh_token SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL"
+	revokeRefreshTokenFamilyQuery  = "UPDATE refresh_token r JOIN refresh_token t ON t.family_id = r.family_id SET r.revoked_at = NOW() WHERE t.token_hash = ? AND t.user_id = ? AND r.revoked_at IS NULL"
+	deleteExpiredRefreshTokenQuery = "DELETE FROM refresh_token WHERE user_id = ? AND expires_at <= NOW()"
+	refreshTokenLen                = 32 // Длина refresh токена в байтах (до кодирования base64)
+	refreshFamilyIDLen             = 16 // Длина идентификатора цепочки в байтах (до кодирования hex)
+)
+
+// RefreshToken обменивает refresh токен на новую пару jwt + refresh токен
+//
+// Основные шаги:
+//  1. Поиск токена по хешу с блокировкой строки
+//  2. Если токен уже обменян, значит его копия у кого-то еще: отзыв всей цепочки
+//  3. Отметка токена использованным и выдача преемника в той же цепочке
+//
+// Параметры:
+//   - ctx: контекст выполнения запроса
+//   - payload: refresh токен
+//
+// Возвращает:
+//   - новый jwt и refresh токен
+//   - ошибку Unauthenticated (токен неизвестен, отозван, истек или использован повторно) или Internal
+func (this *Implementation) RefreshToken(ctx context.Context, payload *pb.RefreshTokenPayload) (*pb.Token, error) {
+	if payload.GetRefreshToken() == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	}
+	hash := hashRefreshToken(payload.GetRefreshToken())
+
+	tx, err := this.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var (
+		familyID string
+		userID   string
+		used     bool
+		revoked  bool
+		expired  bool
+	)
+	err = tx.QueryRowContext(ctx, selectRefreshTokenQuery, hash).Scan(&familyID, &userID, &used, &revoked, &expired)
+	if err != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			log.Println(rollbackErr)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Повторное использование: отзываем цепочку, включая токен, выданный настоящему владельцу
+	if used && !revoked {
+		_, err = tx.ExecContext(ctx, revokeRefreshFamilyQuery, familyID)
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				log.Println(rollbackErr)
+			}
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if err = tx.Commit(); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		log.Printf("user %s: refresh token reuse detected, family %s revoked\n", userID, familyID)
+		return nil, status.Error(codes.Unauthenticated, "refresh token reuse detected, sign in again")
+	}
+
+	if used || revoked || expired {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			log.Println(rollbackErr)
+		}
+		return nil, status.Error(codes.Unauthenticated, "refresh token expired or revoked, sign in again")
+	}
+
+	_, err = tx.ExecContext(ctx, markRefreshTokenUsedQuery, hash)
+	if err != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			log.Println(rollbackErr)
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	refreshToken, err := this.insertRefreshToken(ctx, tx, familyID, userID)
+	if err != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			log.Println(rollbackErr)
+		}
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	jwtToken, err := createJWT(userID, this.accessTTL, this.revocations.issueTime(userID))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Token{Jwt: jwtToken, RefreshToken: refreshToken, ExpiresIn: int64(this.accessTTL.Seconds())}, nil
+}
+
+// issueTokens выдает пару токенов при входе: jwt и первый refresh токен новой цепочки.
+// Заодно удаляет истекшие refresh токены пользователя
+func (this *Implementation) issueTokens(ctx context.Context, userID string) (*pb.Token, error) {
+	_, err := this.db.ExecContext(ctx, deleteExpiredRefreshTokenQuery, userID)
+	if err != nil {
+		log.Printf("user %s: delete expired refresh tokens: %v\n", userID, err)
+	}
+
+	familyID, err := randomString(refreshFamilyIDLen, hex.EncodeToString)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	refreshToken, err := this.insertRefreshToken(ctx, this.db, familyID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	jwtToken, err := createJWT(userID, this.accessTTL, this.revocations.issueTime(userID))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Token{Jwt: jwtToken, RefreshToken: refreshToken, ExpiresIn: int64(this.accessTTL.Seconds())}, nil
+}
+
+// execer - общий метод *sql.DB и *sql.Tx для записи токенов и отзывов
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertRefreshToken создает refresh токен в цепочке familyID
+//
+// Возвращает:
+//   - токен (в БД сохраняется только его хеш)
+//   - ошибку Internal в случае неудачи
+func (this *Implementation) insertRefreshToken(ctx context.Context, db execer, familyID string, userID string) (string, error) {
+	token, err := randomString(refreshTokenLen, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", status.Error(codes.Internal, err.Error())
+	}
+	_, err = db.ExecContext(ctx, insertRefreshTokenQuery, hashRefreshToken(token), familyID, userID, int64(this.refreshTTL.Seconds()))
+	if err != nil {
+		return "", status.Error(codes.Internal, err.Error())
+	}
+	return token, nil
+}
+
+// revokeUserRefreshTokens отзывает все refresh токены пользователя (например, после смены пароля)
+func revokeUserRefreshTokens(ctx context.Context, db execer, userID string) error {
+	_, err := db.ExecContext(ctx, revokeUserRefreshTokensQuery, userID)
+	return err
+}
+
+// revokeRefreshFamily отзывает цепочку, в которую входит refresh токен пользователя userID.
+// Неизвестный или чужой токен игнорируется
+func (this *Implementation) revokeRefreshFamily(ctx context.Context, refreshToken string, userID string) error {
+	_, err := this.db.ExecContext(ctx, revokeRefreshTokenFamilyQuery, hashRefreshToken(refreshToken), userID)
+	return err
+}
+
+// hashRefreshToken вычисляет SHA-256 refresh токена. Токен случайный и длинный,
+// поэтому медленное хеширование, как у паролей, не нужно
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString возвращает n случайных байт в кодировке encode
+func randomString(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
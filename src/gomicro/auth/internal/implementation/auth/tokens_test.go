@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	db := openTestDB(t)
+	impl := newTestImplementation(t, db)
+	userID := createTestUser(t, impl, "Secret123")
+	ctx := context.Background()
+
+	first, err := impl.GetToken(ctx, &pb.Credentials{UserName: userID, Password: "Secret123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := impl.RefreshToken(ctx, &pb.RefreshTokenPayload{RefreshToken: first.GetRefreshToken()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.GetRefreshToken() == first.GetRefreshToken() {
+		t.Fatal("refresh token was not rotated")
+	}
+
+	// Повтор уже обменянного токена: похоже на кражу, отзывается вся цепочка
+	_, err = impl.RefreshToken(ctx, &pb.RefreshTokenPayload{RefreshToken: first.GetRefreshToken()})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("replayed token: expected Unauthenticated, got %v", err)
+	}
+	_, err = impl.RefreshToken(ctx, &pb.RefreshTokenPayload{RefreshToken: second.GetRefreshToken()})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("newest token after reuse: expected Unauthenticated, got %v", err)
+	}
+
+	// Другие входы пользователя не затронуты
+	other, err := impl.GetToken(ctx, &pb.Credentials{UserName: userID, Password: "Secret123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = impl.RefreshToken(ctx, &pb.RefreshTokenPayload{RefreshToken: other.GetRefreshToken()}); err != nil {
+		t.Fatalf("token of another sign-in: %v", err)
+	}
+}
+
+func TestRefreshTokenUnknown(t *testing.T) {
+	db := openTestDB(t)
+	impl := newTestImplementation(t, db)
+
+	_, err := impl.RefreshToken(context.Background(), &pb.RefreshTokenPayload{RefreshToken: "unknown"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated, got %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+const (
+	insertUserQuery         = "INSERT INTO users (user_id, password) VALUES (?, ?)"
+	selectUserQuery         = "SELECT user_id, created_at FROM users WHERE user_id = ?"
+	selectUserPasswordQuery = "SELECT password FROM users WHERE user_id = ?"
+	updateUserPasswordQuery = "UPDATE users SET password = ? WHERE user_id = ? AND password = ?"
+	mysqlErrDuplicateEntry  = 1062 // Код ошибки MySQL при нарушении уникального ключа
+	maxUserIDLen            = 255  // Длина колонки users.user_id
+)
+
+// Register регистрирует нового пользователя
+//
+// Основные шаги:
+//  1. Проверка формата email и политики паролей
+//  2. Хеширование пароля argon2id с текущими параметрами
+//  3. Вставка пользователя; занятый логин - ошибка AlreadyExists
+//
+// Параметры:
+//   - ctx: контекст выполнения запроса
+//   - payload: email и пароль нового пользователя
+//
+// Возвращает:
+//   - созданного пользователя со временем регистрации
+//   - ошибку InvalidArgument, AlreadyExists или Internal
+func (this *Implementation) Register(ctx context.Context, payload *pb.RegisterPayload) (*pb.User, error) {
+	userID, err := validateEmail(payload.GetUserName())
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePassword(payload.GetPassword(), userID); err != nil {
+		return nil, err
+	}
+
+	hash, err := hashPassword(payload.GetPassword(), this.hashParams)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	_, err = this.db.ExecContext(ctx, insertUserQuery, userID, hash)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return nil, status.Error(codes.AlreadyExists, "user already exists")
+		}
+		log.Println(err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return this.fetchUser(ctx, userID)
+}
+
+// ChangePassword меняет пароль владельца токена
+//
+// Текущий пароль проверяется так же, как при входе. Если пароль успел измениться
+// между проверкой и заменой (параллельная смена или rehash при входе),
+// возвращается Aborted, и запрос можно повторить.
+//
+// В одной SQL транзакции с заменой пароля отзываются все сессии пользователя:
+// jwt, выданные до смены (включая токен запроса), и все refresh токены.
+// Пароль не может смениться без отзыва, поэтому после смены нужно войти заново.
+//
+// Параметры:
+//   - ctx: контекст выполнения запроса
+//   - payload: токен, текущий и новый пароль
+//
+// Возвращает:
+//   - пустой ответ при успешной смене
+//   - ошибку Unauthenticated (токен или текущий пароль неверны), InvalidArgument, Aborted или Internal
+func (this *Implementation) ChangePassword(ctx context.Context, payload *pb.ChangePasswordPayload) (*emptypb.Empty, error) {
+	claims, err := this.authenticate(payload.GetJwt())
+	if err != nil {
+		return nil, err
+	}
+	userID := claims.Subject
+
+	var stored string
+	err = this.db.QueryRowContext(ctx, selectUserPasswordQuery, userID).Scan(&stored)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Пользователь удален после выдачи токена
+			return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	ok, _, err := verifyPassword(payload.GetOldPassword(), stored, this.hashParams)
+	if err != nil {
+		log.Printf("user %s: %v\n", userID, err)
+		return nil, status.Error(codes.Internal, "password verification failed")
+	}
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+
+	if payload.GetNewPassword() == payload.GetOldPassword() {
+		return nil, status.Error(codes.InvalidArgument, "new password must differ from the old one")
+	}
+	if err := validatePassword(payload.GetNewPassword(), userID); err != nil {
+		return nil, err
+	}
+
+	hash, err := hashPassword(payload.GetNewPassword(), this.hashParams)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	tx, err := this.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	result, err := tx.ExecContext(ctx, updateUserPasswordQuery, hash, userID, stored)
+	if err != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			log.Println(rollbackErr)
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			log.Println(rollbackErr)
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if updated == 0 {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			log.Println(rollbackErr)
+		}
+		return nil, status.Error(codes.Aborted, "password was changed concurrently, try again")
+	}
+
+	// Отзыв jwt и refresh токенов, выданных со старым паролем
+	revokedAt, err := insertUserRevocation(ctx, tx, userID)
+	if err != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			log.Println(rollbackErr)
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	err = revokeUserRefreshTokens(ctx, tx, userID)
+	if err != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			log.Println(rollbackErr)
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	this.revocations.cacheUserRevocation(userID, revokedAt)
+
+	log.Printf("user %s: password changed, all sessions revoked\n", userID)
+	return &emptypb.Empty{}, nil
+}
+
+// GetUser возвращает профиль владельца токена
+//
+// Параметры:
+//   - ctx: контекст выполнения запроса
+//   - token: JWT пользователя
+//
+// Возвращает:
+//   - пользователя со временем регистрации
+//   - ошибку Unauthenticated или Internal
+func (this *Implementation) GetUser(ctx context.Context, token *pb.Token) (*pb.User, error) {
+	claims, err := this.authenticate(token.GetJwt())
+	if err != nil {
+		return nil, err
+	}
+	userID := claims.Subject
+
+	user, err := this.fetchUser(ctx, userID)
+	if status.Code(err) == codes.NotFound {
+		// Пользователь удален после выдачи токена
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	return user, err
+}
+
+// fetchUser загружает пользователя userID из БД
+func (this *Implementation) fetchUser(ctx context.Context, userID string) (*pb.User, error) {
+	var createdAt time.Time
+	err := this.db.QueryRowContext(ctx, selectUserQuery, userID).Scan(&userID, &createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.User{UserID: userID, CreatedAt: timestamppb.New(createdAt)}, nil
+}
+
+// validateEmail проверяет, что логин - одиночный email без отображаемого имени
+// (например, "user@example.com", но не "User <user@example.com>")
+//
+// Возвращает:
+//   - email без пробелов по краям
+//   - ошибку InvalidArgument, если формат неверный
+func validateEmail(userName string) (string, error) {
+	userName = strings.TrimSpace(userName)
+	if userName == "" {
+		return "", status.Error(codes.InvalidArgument, "email is required")
+	}
+	if len(userName) > maxUserIDLen {
+		return "", status.Error(codes.InvalidArgument, fmt.Sprintf("email must be at most %d characters", maxUserIDLen))
+	}
+
+	addr, err := mail.ParseAddress(userName)
+	if err != nil || addr.Name != "" || addr.Address != userName {
+		return "", status.Error(codes.InvalidArgument, "invalid email format")
+	}
+	// net/mail допускает адреса без домена верхнего уровня (user@localhost)
+	domain := userName[strings.LastIndex(userName, "@")+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", status.Error(codes.InvalidArgument, "invalid email domain")
+	}
+	return userName, nil
+}
+
+// isDuplicateEntry сообщает, что запрос нарушил уникальный ключ таблицы
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
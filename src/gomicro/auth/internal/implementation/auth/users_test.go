@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestChangePasswordRevokesSessions(t *testing.T) {
+	db := openTestDB(t)
+	impl := newTestImplementation(t, db)
+	userID := createTestUser(t, impl, "Secret123")
+	ctx := context.Background()
+
+	token, err := impl.GetToken(ctx, &pb.Credentials{UserName: userID, Password: "Secret123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = impl.ChangePassword(ctx, &pb.ChangePasswordPayload{Jwt: token.GetJwt(), OldPassword: "Secret123", NewPassword: "Secret456"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Сессии, открытые со старым паролем, отозваны
+	if _, err = impl.ValidateToken(ctx, &pb.Token{Jwt: token.GetJwt()}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("jwt issued before change: expected Unauthenticated, got %v", err)
+	}
+	_, err = impl.RefreshToken(ctx, &pb.RefreshTokenPayload{RefreshToken: token.GetRefreshToken()})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("refresh token issued before change: expected Unauthenticated, got %v", err)
+	}
+
+	var revocations int
+	if err = db.QueryRow("SELECT COUNT(*) FROM user_revocation WHERE user_id = ?", userID).Scan(&revocations); err != nil {
+		t.Fatal(err)
+	}
+	if revocations != 1 {
+		t.Errorf("user_revocation rows = %d, want 1", revocations)
+	}
+
+	_, err = impl.GetToken(ctx, &pb.Credentials{UserName: userID, Password: "Secret123"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("old password: expected Unauthenticated, got %v", err)
+	}
+	fresh, err := impl.GetToken(ctx, &pb.Credentials{UserName: userID, Password: "Secret456"})
+	if err != nil {
+		t.Fatalf("new password: %v", err)
+	}
+
+	// Вход сразу после смены пароля получает действующий jwt
+	if _, err = impl.ValidateToken(ctx, &pb.Token{Jwt: fresh.GetJwt()}); err != nil {
+		t.Fatalf("jwt issued after change: %v", err)
+	}
+}
@@ -6,16 +6,30 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/IBM/sarama"
+	"github.com/sunr3d/gomicro/events"
 	"github.com/sunr3d/gomicro/internal/email"
+	"google.golang.org/protobuf/proto"
 	"log"
 	"os"
 	"sync"
 )
 
-const topic = "email"
+const (
+	topic        = "email"
+	paymentTopic = "payment" // События жизненного цикла платежей
+)
+
+// Формат сообщений топика: конверт events.Envelope или JSON (сообщения до появления конверта)
+const (
+	contentTypeHeader   = "content-type"
+	contentTypeJSON     = "application/json"
+	contentTypeEnvelope = "application/x-protobuf; type=events.Envelope"
+	envelopeVersion     = 2 // Последняя известная версия схем EmailNotification и PaymentEvent (в версии 2 они не менялись)
+)
 
 var wg sync.WaitGroup
 
+// EmailMsg - сообщение в JSON, которое публиковалось до появления конверта
 type EmailMsg struct {
 	OrderID string `json:"order_id"`
 	UserID  string `json:"user_id"`
@@ -37,29 +51,32 @@ func main() {
 		}
 	}()
 
-	// Получаем лист партиций из конкретного раздела (в нашем случае дефайнд как email)
-	partitions, err := consumer.Partitions(topic)
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	// Т.к. партиций может быть несколько
-	// Создаем цикл в котором из списка партиций забираем каждую по отдельности с помощью партишнКонсюмера
-	// для обработки сообщений используется функция awaitMessages
-	for _, partition := range partitions {
-		pc, err := consumer.ConsumePartition(topic, partition, sarama.OffsetNewest)
+	// Уведомления об операциях со счетами и о переходах платежей
+	for _, t := range []string{topic, paymentTopic} {
+		// Получаем лист партиций из конкретного раздела
+		partitions, err := consumer.Partitions(t)
 		if err != nil {
 			log.Fatalln(err)
 		}
 
-		defer func() {
-			if err := pc.Close(); err != nil {
-				log.Println(err)
+		// Т.к. партиций может быть несколько
+		// Создаем цикл в котором из списка партиций забираем каждую по отдельности с помощью партишнКонсюмера
+		// для обработки сообщений используется функция awaitMessages
+		for _, partition := range partitions {
+			pc, err := consumer.ConsumePartition(t, partition, sarama.OffsetNewest)
+			if err != nil {
+				log.Fatalln(err)
 			}
-		}()
 
-		wg.Add(1)
-		go awaitMessages(pc, partition, done)
+			defer func() {
+				if err := pc.Close(); err != nil {
+					log.Println(err)
+				}
+			}()
+
+			wg.Add(1)
+			go awaitMessages(pc, partition, done)
+		}
 	}
 
 	wg.Wait()
@@ -87,17 +104,88 @@ func awaitMessages(pc sarama.PartitionConsumer, partition int32, done chan struc
 
 // Функция обработки сообщения из партиции Кафки
 func handleMessage(msg *sarama.ConsumerMessage) {
-	// Перевод сообщения из формата JSON в Го-структуру
-	var emailMsg EmailMsg
-	if err := json.Unmarshal(msg.Value, &emailMsg); err != nil {
+	if msg.Topic == paymentTopic {
+		handlePaymentEvent(msg)
+		return
+	}
+
+	notification, err := decodeMessage(msg)
+	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
 	// Отправка сообщения клиенту по е-мейл через функцию Send из кастомного пакета email
-	err := email.Send(emailMsg.UserID, emailMsg.OrderID)
+	err = email.Send(notification.UserId, notification.OrderId)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 }
+
+// handlePaymentEvent уведомляет покупателя о переходе платежа
+func handlePaymentEvent(msg *sarama.ConsumerMessage) {
+	var envelope events.Envelope
+	if err := proto.Unmarshal(msg.Value, &envelope); err != nil {
+		fmt.Println(err)
+		return
+	}
+	event := &events.PaymentEvent{}
+	if err := unwrap(&envelope, event); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	err := email.SendPaymentEvent(event.Customer.GetUserId(), event.Pid, event.Type.String(), event.Amount, event.Currency)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// decodeMessage разбирает сообщение топика в EmailNotification.
+// Сообщение без заголовка content-type - старый JSON, конверт принимается
+// в версиях до envelopeVersion включительно
+func decodeMessage(msg *sarama.ConsumerMessage) (*events.EmailNotification, error) {
+	contentType := contentTypeJSON
+	for _, h := range msg.Headers {
+		if string(h.Key) == contentTypeHeader {
+			contentType = string(h.Value)
+		}
+	}
+
+	switch contentType {
+	case contentTypeJSON:
+		// Перевод сообщения из формата JSON в Го-структуру
+		var emailMsg EmailMsg
+		if err := json.Unmarshal(msg.Value, &emailMsg); err != nil {
+			return nil, err
+		}
+		return &events.EmailNotification{OrderId: emailMsg.OrderID, UserId: emailMsg.UserID}, nil
+
+	case contentTypeEnvelope:
+		var envelope events.Envelope
+		if err := proto.Unmarshal(msg.Value, &envelope); err != nil {
+			return nil, err
+		}
+		notification := &events.EmailNotification{}
+		if err := unwrap(&envelope, notification); err != nil {
+			return nil, err
+		}
+		return notification, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", contentType)
+	}
+}
+
+// unwrap проверяет тип и версию конверта и разбирает его payload в payload
+func unwrap(envelope *events.Envelope, payload proto.Message) error {
+	if envelope.Type != string(payload.ProtoReflect().Descriptor().FullName()) {
+		return fmt.Errorf("event %s: unexpected type %q", envelope.EventId, envelope.Type)
+	}
+	if envelope.Version == 0 || envelope.Version > envelopeVersion {
+		return fmt.Errorf("event %s: unsupported version %d of %s", envelope.EventId, envelope.Version, envelope.Type)
+	}
+	return proto.Unmarshal(envelope.Payload, payload)
+}
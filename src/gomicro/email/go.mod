@@ -2,7 +2,10 @@ module github.com/sunr3d/gomicro
 
 go 1.23.2
 
-require github.com/IBM/sarama v1.43.3
+require (
+	github.com/IBM/sarama v1.43.3
+	google.golang.org/protobuf v1.35.2
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
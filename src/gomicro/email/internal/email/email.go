@@ -9,6 +9,32 @@ import (
 
 // Send отправляет сообщение о транзакции (orderID) клиенту target
 func Send(target string, orderID string) error {
+	// Сообщение для отправки
+	return send(target, []byte(fmt.Sprintf("Subject: Payment Processed!\n Process ID: %s\n", orderID)))
+}
+
+// paymentSubjects - темы писем о переходах платежа
+var paymentSubjects = map[string]string{
+	"PAYMENT_AUTHORIZED": "Payment Authorized",
+	"PAYMENT_CAPTURED":   "Payment Processed!",
+	"PAYMENT_VOIDED":     "Payment Cancelled",
+	"PAYMENT_REFUNDED":   "Payment Refunded",
+	"PAYMENT_EXPIRED":    "Payment Authorization Expired",
+}
+
+// SendPaymentEvent отправляет клиенту target сообщение о переходе платежа pid
+// (eventType - имя events.PaymentEventType) на сумму amount в валюте currency
+func SendPaymentEvent(target string, pid string, eventType string, amount int64, currency string) error {
+	subject, ok := paymentSubjects[eventType]
+	if !ok {
+		return fmt.Errorf("unknown payment event type %s", eventType)
+	}
+	// Сумма указывается в минорных единицах: их число в основной единице зависит от валюты
+	return send(target, []byte(fmt.Sprintf("Subject: %s\n Process ID: %s\n Amount: %d %s minor units\n", subject, pid, amount, currency)))
+}
+
+// send отправляет сообщение message клиенту target через SMTP
+func send(target string, message []byte) error {
 	// Данные отправителя
 	senderEmail := os.Getenv("SENDER_EMAIL")
 	password := os.Getenv("SENDER_PASSWORD")
@@ -24,9 +50,6 @@ func Send(target string, orderID string) error {
 	// Данные авторизации отправителя типа Auth
 	creds := smtp.PlainAuth("", senderEmail, password, smtpServer)
 
-	// Сообщение для отправки
-	message := []byte(fmt.Sprintf("Subject: Payment Processed!\n Process ID: %s\n", orderID))
-
 	// Отправка сообщения через протокол SMTP
 	err := smtp.SendMail(smtpAddress, creds, senderEmail, []string{recipientEmail}, message)
 	if err != nil {
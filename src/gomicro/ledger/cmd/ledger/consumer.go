@@ -6,16 +6,27 @@ import (
 	"fmt"
 	"github.com/IBM/sarama"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/sunr3d/gomicro/events"
 	"github.com/sunr3d/gomicro/internal/ledger"
+	"google.golang.org/protobuf/proto"
 	"log"
 	"os"
 	"sync"
 )
 
 const (
-	topic    = "ledger"
-	dbDriver = "mysql"  // Драйвер базы данных
-	dbName   = "ledger" // Имя базы данных
+	topic        = "ledger"
+	paymentTopic = "payment" // События жизненного цикла платежей
+	dbDriver     = "mysql"   // Драйвер базы данных
+	dbName       = "ledger"  // Имя базы данных
+)
+
+// Формат сообщений топика: конверт events.Envelope или JSON (сообщения до появления конверта)
+const (
+	contentTypeHeader   = "content-type"
+	contentTypeJSON     = "application/json"
+	contentTypeEnvelope = "application/x-protobuf; type=events.Envelope"
+	envelopeVersion     = 2 // Последняя известная версия схем LedgerEntry и PaymentEvent (2 - валюта в LedgerEntry)
 )
 
 var (
@@ -23,12 +34,14 @@ var (
 	wg sync.WaitGroup
 )
 
+// LedgerMsg - сообщение в JSON, которое публиковалось до появления конверта
 type LedgerMsg struct {
-	OrderID   string `json:"order_id"`
-	UserID    string `json:"user_id"`
-	Amount    int64  `json:"amount"`
-	Operation string `json:"operation"`
-	Date      string `json:"date"`
+	OrderID   string      `json:"order_id"`
+	UserID    string      `json:"user_id"`
+	Amount    int64       `json:"amount"`
+	Operation string      `json:"operation"`
+	Date      string      `json:"date"`
+	Fee       *ledger.Fee `json:"fee,omitempty"` // Разбивка суммы подтверждения на комиссию и выручку продавца
 }
 
 func main() {
@@ -73,29 +86,32 @@ func main() {
 		}
 	}()
 
-	// Получаем лист партиций из конкретного раздела (в нашем случае дефайнд как email)
-	partitions, err := consumer.Partitions(topic)
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	// Т.к. партиций может быть несколько
-	// Создаем цикл в котором из списка партиций забираем каждую по отдельности с помощью партишнКонсюмера
-	// для обработки сообщений используется функция awaitMessages
-	for _, partition := range partitions {
-		pc, err := consumer.ConsumePartition(topic, partition, sarama.OffsetNewest)
+	// Леджер читает свои проводки и журнал переходов платежей
+	for _, t := range []string{topic, paymentTopic} {
+		// Получаем лист партиций из конкретного раздела
+		partitions, err := consumer.Partitions(t)
 		if err != nil {
 			log.Fatalln(err)
 		}
 
-		defer func() {
-			if err := pc.Close(); err != nil {
-				log.Println(err)
+		// Т.к. партиций может быть несколько
+		// Создаем цикл в котором из списка партиций забираем каждую по отдельности с помощью партишнКонсюмера
+		// для обработки сообщений используется функция awaitMessages
+		for _, partition := range partitions {
+			pc, err := consumer.ConsumePartition(t, partition, sarama.OffsetNewest)
+			if err != nil {
+				log.Fatalln(err)
 			}
-		}()
 
-		wg.Add(1)
-		go awaitMessages(pc, partition, done)
+			defer func() {
+				if err := pc.Close(); err != nil {
+					log.Println(err)
+				}
+			}()
+
+			wg.Add(1)
+			go awaitMessages(pc, partition, done)
+		}
 	}
 
 	wg.Wait()
@@ -124,17 +140,122 @@ func awaitMessages(pc sarama.PartitionConsumer, partition int32, done chan struc
 
 // Функция обработки сообщения из партиции Кафки
 func handleMessage(msg *sarama.ConsumerMessage) {
-	// Перевод сообщения из формата JSON в Го-структуру
-	var ledgerMsg LedgerMsg
-	if err := json.Unmarshal(msg.Value, &ledgerMsg); err != nil {
+	if msg.Topic == paymentTopic {
+		handlePaymentEvent(msg)
+		return
+	}
+
+	entry, eventID, err := decodeMessage(msg)
+	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
+	var fee *ledger.Fee
+	if entry.Fee != nil {
+		fee = &ledger.Fee{
+			MerchantUserID: entry.Fee.MerchantUserId,
+			PercentBps:     entry.Fee.PercentBps,
+			FixedCents:     entry.Fee.FixedCents,
+			FeeCents:       entry.Fee.FeeCents,
+			NetCents:       entry.Fee.NetCents,
+		}
+	}
+
 	// Отправка сообщения в Леджер через функцию Insert из кастомного пакета ledger
-	err := ledger.Insert(db, ledgerMsg.OrderID, ledgerMsg.UserID, ledgerMsg.Amount, ledgerMsg.Operation, ledgerMsg.Date)
+	err = ledger.Insert(db, eventID, entry.OrderId, entry.UserId, entry.Amount, entry.Currency, entry.Operation, entry.Date, fee)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 }
+
+// handlePaymentEvent записывает переход платежа в журнал леджера
+func handlePaymentEvent(msg *sarama.ConsumerMessage) {
+	var envelope events.Envelope
+	if err := proto.Unmarshal(msg.Value, &envelope); err != nil {
+		fmt.Println(err)
+		return
+	}
+	event := &events.PaymentEvent{}
+	if err := unwrap(&envelope, event); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	err := ledger.InsertPaymentEvent(db, envelope.EventId, envelope.OccurredAt.AsTime(), event)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// decodeMessage разбирает сообщение топика в LedgerEntry
+//
+// Формат определяется заголовком content-type: без заголовка сообщение считается
+// старым JSON. В конверте принимаются версии до envelopeVersion включительно -
+// новые версии только добавляют поля, а отсутствующие поля остаются пустыми.
+//
+// Возвращает:
+//   - операцию
+//   - идентификатор события из конверта (пустой для JSON)
+//   - ошибку, если сообщение не разбирается
+func decodeMessage(msg *sarama.ConsumerMessage) (*events.LedgerEntry, string, error) {
+	contentType := contentTypeJSON
+	for _, h := range msg.Headers {
+		if string(h.Key) == contentTypeHeader {
+			contentType = string(h.Value)
+		}
+	}
+
+	switch contentType {
+	case contentTypeJSON:
+		// Перевод сообщения из формата JSON в Го-структуру
+		var ledgerMsg LedgerMsg
+		if err := json.Unmarshal(msg.Value, &ledgerMsg); err != nil {
+			return nil, "", err
+		}
+		entry := &events.LedgerEntry{
+			OrderId:   ledgerMsg.OrderID,
+			UserId:    ledgerMsg.UserID,
+			Amount:    ledgerMsg.Amount,
+			Operation: ledgerMsg.Operation,
+			Date:      ledgerMsg.Date,
+		}
+		if ledgerMsg.Fee != nil {
+			entry.Fee = &events.Fee{
+				MerchantUserId: ledgerMsg.Fee.MerchantUserID,
+				PercentBps:     ledgerMsg.Fee.PercentBps,
+				FixedCents:     ledgerMsg.Fee.FixedCents,
+				FeeCents:       ledgerMsg.Fee.FeeCents,
+				NetCents:       ledgerMsg.Fee.NetCents,
+			}
+		}
+		return entry, "", nil
+
+	case contentTypeEnvelope:
+		var envelope events.Envelope
+		if err := proto.Unmarshal(msg.Value, &envelope); err != nil {
+			return nil, "", err
+		}
+		entry := &events.LedgerEntry{}
+		if err := unwrap(&envelope, entry); err != nil {
+			return nil, "", err
+		}
+		return entry, envelope.EventId, nil
+
+	default:
+		return nil, "", fmt.Errorf("unsupported content type %q", contentType)
+	}
+}
+
+// unwrap проверяет тип и версию конверта и разбирает его payload в payload
+func unwrap(envelope *events.Envelope, payload proto.Message) error {
+	if envelope.Type != string(payload.ProtoReflect().Descriptor().FullName()) {
+		return fmt.Errorf("event %s: unexpected type %q", envelope.EventId, envelope.Type)
+	}
+	if envelope.Version == 0 || envelope.Version > envelopeVersion {
+		return fmt.Errorf("event %s: unsupported version %d of %s", envelope.EventId, envelope.Version, envelope.Type)
+	}
+	return proto.Unmarshal(envelope.Payload, payload)
+}
@@ -5,6 +5,7 @@ go 1.23.2
 require (
 	github.com/IBM/sarama v1.43.3
 	github.com/go-sql-driver/mysql v1.8.1
+	google.golang.org/protobuf v1.35.2
 )
 
 require (
@@ -9,12 +9,36 @@ USE ledger; -- Переключаемся на БД
 -- Создание таблицы транзакций:
 CREATE TABLE transaction (
     id INT NOT NULL AUTO_INCREMENT PRIMARY KEY, -- Уникальный идентификатор с автоинкрементом
+    event_id VARCHAR(36), -- Идентификатор события (повторная доставка не создает дублей; пусто у сообщений в JSON)
     order_id VARCHAR(255) NOT NULL, -- Идентификатор платежа
     user_id VARCHAR(255) NOT NULL, -- Идентификатор покупателя
-    amount INT NOT NULL, -- Сумма транзакции в центах
+    amount BIGINT NOT NULL, -- Сумма транзакции в минорных единицах валюты
+    currency CHAR(3), -- Валюта суммы (пусто у сообщений, опубликованных до ее появления)
     operation VARCHAR(255) NOT NULL, -- Название операции
     date DATE NOT NULL, -- Дата транзакции
+    merchant_user_id VARCHAR(255), -- Продавец (только для подтверждений платежа)
+    fee_amount BIGINT, -- Комиссия платформы с подтверждения
+    net_amount BIGINT, -- Сумма, зачисленная продавцу после комиссии
+    UNIQUE(event_id),
     INDEX(order_id) -- Индексирование по идентификатору платежа для быстрого поиска
 );
 
-
+-- Создание журнала переходов платежей (AUTHORIZED, CAPTURED, VOIDED, REFUNDED, EXPIRED):
+-- по строке на каждого продавца, которого касается переход
+CREATE TABLE payment_event (
+    id INT NOT NULL AUTO_INCREMENT PRIMARY KEY, -- Уникальный идентификатор с автоинкрементом
+    event_id VARCHAR(36) NOT NULL, -- Идентификатор события (повторная доставка не создает дублей)
+    pid VARCHAR(255) NOT NULL, -- Идентификатор платежа
+    event_type VARCHAR(32) NOT NULL, -- Переход
+    status VARCHAR(32) NOT NULL, -- Состояние платежа после перехода
+    customer_user_id VARCHAR(255) NOT NULL, -- Покупатель
+    customer_wallet_id INT NOT NULL,
+    merchant_user_id VARCHAR(255) NOT NULL, -- Продавец
+    merchant_wallet_id INT NOT NULL,
+    amount BIGINT NOT NULL, -- Сумма перехода по доле продавца в центах
+    fee_amount BIGINT, -- Комиссия платформы (только CAPTURED)
+    currency CHAR(3) NOT NULL, -- Валюта платежа
+    occurred_at TIMESTAMP NOT NULL, -- Время перехода
+    UNIQUE(event_id, merchant_wallet_id),
+    INDEX(pid) -- Индексирование по идентификатору платежа для быстрого поиска
+);
@@ -1,18 +1,84 @@
 package ledger
 
-import "database/sql"
+import (
+	"database/sql"
+	"github.com/sunr3d/gomicro/events"
+	"time"
+)
 
-func Insert(db *sql.DB, orderID string, userID string, amount int64, operation string, date string) error {
-	stmt, err := db.Prepare("INSERT INTO transaction(order_id, user_id, amount, operation, date) VALUES (?, ?, ?, ?, ?)")
+// Fee - разбивка подтвержденной суммы между продавцом и платформой
+type Fee struct {
+	MerchantUserID string `json:"merchant_user_id"`
+	PercentBps     int32  `json:"percent_bps"`
+	FixedCents     int64  `json:"fixed_cents"`
+	FeeCents       int64  `json:"fee_cents"`
+	NetCents       int64  `json:"net_cents"`
+}
+
+// Insert записывает операцию в леджер: amount в минорных единицах валюты currency
+// (пустая у старых сообщений). fee передается только для подтверждений платежа.
+// Повторно доставленное событие (с тем же eventID) пропускается; у сообщений в JSON
+// идентификатора события нет (eventID пустой), и они записываются всегда
+func Insert(db *sql.DB, eventID string, orderID string, userID string, amount int64, currency string, operation string, date string, fee *Fee) error {
+	stmt, err := db.Prepare("INSERT IGNORE INTO transaction(event_id, order_id, user_id, amount, currency, operation, date, merchant_user_id, fee_amount, net_amount) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(orderID, userID, amount, operation, date)
+	var (
+		event          = sql.NullString{String: eventID, Valid: eventID != ""}
+		currencyCode   = sql.NullString{String: currency, Valid: currency != ""}
+		merchantUserID sql.NullString
+		feeAmount      sql.NullInt64
+		netAmount      sql.NullInt64
+	)
+	if fee != nil {
+		merchantUserID = sql.NullString{String: fee.MerchantUserID, Valid: true}
+		feeAmount = sql.NullInt64{Int64: fee.FeeCents, Valid: true}
+		netAmount = sql.NullInt64{Int64: fee.NetCents, Valid: true}
+	}
+
+	_, err = stmt.Exec(event, orderID, userID, amount, currencyCode, operation, date, merchantUserID, feeAmount, netAmount)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// InsertPaymentEvent записывает переход платежа в журнал, по строке на каждого продавца.
+// Повторно доставленное событие (с тем же eventID) пропускается
+func InsertPaymentEvent(db *sql.DB, eventID string, occurredAt time.Time, event *events.PaymentEvent) error {
+	stmt, err := db.Prepare("INSERT IGNORE INTO payment_event(event_id, pid, event_type, status, customer_user_id, customer_wallet_id, merchant_user_id, merchant_wallet_id, amount, fee_amount, currency, occurred_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, share := range event.Merchants {
+		var feeAmount sql.NullInt64
+		if share.Fee != nil {
+			feeAmount = sql.NullInt64{Int64: share.Fee.FeeCents, Valid: true}
+		}
+
+		_, err = stmt.Exec(
+			eventID,
+			event.Pid,
+			event.Type.String(),
+			event.Status,
+			event.Customer.GetUserId(),
+			event.Customer.GetWalletId(),
+			share.Merchant.GetUserId(),
+			share.Merchant.GetWalletId(),
+			share.Amount,
+			feeAmount,
+			event.Currency,
+			occurredAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
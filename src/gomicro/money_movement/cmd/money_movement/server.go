@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
 	"database/sql" // Стандартный пакет для работы с SQL базами данных
 	"fmt"          // Пакет для форматированного ввода/вывода
 	_ "github.com/go-sql-driver/mysql"
 	mm "github.com/sunr3d/gomicro/internal/implementation"
+	"github.com/sunr3d/gomicro/internal/payout"
+	"github.com/sunr3d/gomicro/internal/producer"
 	pb "github.com/sunr3d/gomicro/proto" // Протобаф сервис для gRPC
 	"google.golang.org/grpc"             // Библиотека для gRPC
 	"log"                                // Пакет логирования
 	"net"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
 // Константы подключения к БД (дефайны)
@@ -18,6 +27,24 @@ const (
 	dbName   = "money_movement" // Имя базы данных
 )
 
+// Значения по умолчанию для настроек из переменных окружения
+const (
+	defaultAuthorizationTTL    = 7 * 24 * time.Hour // Срок действия авторизации
+	defaultExpirySweepInterval = time.Minute        // Интервал поиска истекших авторизаций
+	defaultFxQuoteTTL          = 5 * time.Minute    // Срок действия котировки конвертации
+	defaultFxSpreadBps         = 50                 // Спред обменного пункта (0.5%)
+	defaultPayoutInterval      = 10 * time.Second   // Интервал обработки выплат продавцам
+	defaultOutboxInterval      = time.Second        // Интервал отправки событий из outbox в Кафку
+	defaultFakeBankSettleDelay = 30 * time.Second   // Время отправки выплаты фейковым банком
+)
+
+// Настройки Кафки по умолчанию
+const (
+	defaultKafkaBrokers     = "my-cluster-kafka-bootstrap:9092" // Адреса брокеров через запятую
+	defaultKafkaAcks        = "leader"                          // Подтверждение записи лидером партиции
+	defaultKafkaCompression = "none"
+)
+
 var db *sql.DB // Глобал переменная для базы данных
 
 func main() {
@@ -25,39 +52,116 @@ func main() {
 	dbUser := os.Getenv("MYSQL_USER")         // Имя пользователя БД
 	dbPassword := os.Getenv("MYSQL_PASSWORD") // Пароль (!ВАЖНО: никогда не хранить так в реальном проекте!)
 	/// БЛОК DataBase(!)
-	// Формирование строки подключения к БД (dsn = Data Source Name)
-	dsn := fmt.Sprintf("%s:%s@tcp(mysql-money-movement:3306)/%s", dbUser, dbPassword, dbName)
+	// STORAGE=memory запускает сервис без БД: данные хранятся в памяти процесса
+	// и теряются при перезапуске (для локальной разработки)
+	var store mm.Store
+	if os.Getenv("STORAGE") == "memory" {
+		log.Println("using in-memory storage")
+		store = mm.NewMemoryStore()
+	} else {
+		// Формирование строки подключения к БД (dsn = Data Source Name)
+		// parseTime=true нужен для чтения колонок TIMESTAMP в time.Time
+		dsn := fmt.Sprintf("%s:%s@tcp(mysql-money-movement:3306)/%s?parseTime=true", dbUser, dbPassword, dbName)
 
-	// Открытие соединения с базой данных
-	db, err = sql.Open(dbDriver, dsn)
-	if err != nil {
-		log.Fatalln(err) // Завершение программы при ошибке подключения
-	}
+		// Открытие соединения с базой данных
+		db, err = sql.Open(dbDriver, dsn)
+		if err != nil {
+			log.Fatalln(err) // Завершение программы при ошибке подключения
+		}
 
-	// Отложенное закрытие соединения с БД через анонимную функцию
-	defer func() {
-		if err := db.Close(); err != nil {
-			log.Printf("Error closing DB: %s", err)
+		// Отложенное закрытие соединения с БД через анонимную функцию
+		defer func() {
+			if err := db.Close(); err != nil {
+				log.Printf("Error closing DB: %s", err)
+			}
+		}()
+
+		// Проверка работоспособности соединения
+		err = db.Ping()
+		if err != nil {
+			log.Fatalln(err) // Завершение программы при отсутствии связи
 		}
-	}()
 
-	// Проверка работоспособности соединения
-	err = db.Ping()
-	if err != nil {
-		log.Fatalln(err) // Завершение программы при отсутствии связи
+		store = mm.NewMySQLStore(db)
 	}
 	/// БЛОК DataBase(!)
 
+	/// БЛОК Kafka(!)
+	// Продюсер создается один раз и закрывается после остановки отправки событий из outbox.
+	// К брокерам он подключается при первой отправке, поэтому сервер запускается
+	// и без Кафки: события ждут в outbox, а relay повторяет подключение
+	publisher, err := producer.NewKafkaPublisher(producer.Config{
+		Brokers:      strings.Split(stringFromEnv("KAFKA_BROKERS", defaultKafkaBrokers), ","),
+		RequiredAcks: stringFromEnv("KAFKA_ACKS", defaultKafkaAcks),
+		Compression:  stringFromEnv("KAFKA_COMPRESSION", defaultKafkaCompression),
+		Idempotent:   os.Getenv("KAFKA_IDEMPOTENT") == "true",
+	})
+	if err != nil {
+		log.Fatalf("invalid kafka producer config: %v\n", err)
+	}
+	defer func() {
+		if err := publisher.Close(); err != nil {
+			log.Printf("Error closing kafka producer: %s", err)
+		}
+	}()
+	/// БЛОК Kafka(!)
+
 	/// БЛОК gRPC SERVER(!)
 	// Создание нового ПУСТОГО gRPC сервера
 	grpcServer := grpc.NewServer()
-	pb.RegisterMoneyMovementServiceServer(grpcServer, mm.NewMoneyMovementImplementation(db))
+	mmImplementation := mm.NewMoneyMovementImplementation(
+		store,
+		durationFromEnv("AUTHORIZATION_TTL", defaultAuthorizationTTL),
+		durationFromEnv("FX_QUOTE_TTL", defaultFxQuoteTTL),
+		spreadFromEnv("FX_SPREAD_BPS", defaultFxSpreadBps),
+		// Пока реальный банк не подключен, выплаты обрабатывает фейковый банк
+		payout.NewFakeBank(
+			durationFromEnv("FAKE_BANK_SETTLE_DELAY", defaultFakeBankSettleDelay),
+			centsFromEnv("FAKE_BANK_MAX_CENTS")),
+		publisher)
+	pb.RegisterMoneyMovementServiceServer(grpcServer, mmImplementation)
+
+	// Загрузка курсов валют из файла (если задан), дальше курсы обновляются методом SetFxRates
+	if path := os.Getenv("FX_RATES_FILE"); path != "" {
+		if err := mmImplementation.LoadFxRatesFile(context.Background(), path); err != nil {
+			log.Fatalf("failed to load fx rates: %v\n", err)
+		}
+	}
 
 	listener, err := net.Listen("tcp", ":7000")
 	if err != nil {
 		log.Fatalf("failed to listen on port 7000: %v\n", err)
 	}
 
+	// SIGINT/SIGTERM останавливают сервер и фоновые обработчики
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
+	workers.Add(3)
+	// Запуск фонового обработчика истекших авторизаций
+	go func() {
+		defer workers.Done()
+		mmImplementation.RunExpirySweeper(ctx, durationFromEnv("EXPIRY_SWEEP_INTERVAL", defaultExpirySweepInterval))
+	}()
+	// Запуск фонового обработчика выплат продавцам
+	go func() {
+		defer workers.Done()
+		mmImplementation.RunPayoutWorker(ctx, durationFromEnv("PAYOUT_INTERVAL", defaultPayoutInterval))
+	}()
+	// Запуск отправки событий из outbox в Кафку
+	go func() {
+		defer workers.Done()
+		mmImplementation.RunOutboxRelay(ctx, durationFromEnv("OUTBOX_INTERVAL", defaultOutboxInterval))
+	}()
+
+	// Остановка сервера по сигналу: новые запросы не принимаются, текущие завершаются
+	go func() {
+		<-ctx.Done()
+		log.Println("shutting down")
+		grpcServer.GracefulStop()
+	}()
+
 	// Логирование адреса сервера
 	log.Printf("server is listening at %v\n", listener.Addr())
 
@@ -65,5 +169,62 @@ func main() {
 	if err := grpcServer.Serve(listener); err != nil {
 		log.Fatalf("failed to serve grpc server: %v\n", err)
 	}
+
+	// Продюсер и БД закрываются отложенными вызовами после остановки обработчиков
+	workers.Wait()
 	/// БЛОК gRPC SERVER(!)
 }
+
+// stringFromEnv читает строку из переменной окружения name.
+// Если переменная не задана, возвращается значение по умолчанию def
+func stringFromEnv(name string, def string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return def
+}
+
+// durationFromEnv читает длительность (например "168h" или "30s") из переменной окружения name.
+// Если переменная не задана, возвращается значение по умолчанию def
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Fatalf("invalid %s %q: must be a positive duration\n", name, value)
+	}
+	return d
+}
+
+// spreadFromEnv читает спред в базисных пунктах (от 0 до 9999) из переменной окружения name.
+// Если переменная не задана, возвращается значение по умолчанию def
+func spreadFromEnv(name string, def int32) int32 {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	bps, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || bps < 0 || bps >= 10000 {
+		log.Fatalf("invalid %s %q: must be between 0 and 9999 basis points\n", name, value)
+	}
+	return int32(bps)
+}
+
+// centsFromEnv читает неотрицательную сумму в минорных единицах из переменной окружения name.
+// Если переменная не задана, возвращается 0
+func centsFromEnv(name string) int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+
+	cents, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || cents < 0 {
+		log.Fatalf("invalid %s %q: must be a non-negative amount\n", name, value)
+	}
+	return cents
+}
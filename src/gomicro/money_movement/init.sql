@@ -10,17 +10,19 @@ USE money_movement; -- Переключаемся на БД
 CREATE TABLE wallet (
     id INT NOT NULL AUTO_INCREMENT PRIMARY KEY, -- Уникальный идентификатор с автоинкрементом
     user_id VARCHAR(255) NOT NULL UNIQUE, -- Идентификатор пользователя
-    wallet_type VARCHAR(255) NOT NULL, -- Тип кошелька (CUSTOMER/MERCHANT)
+    wallet_type VARCHAR(255) NOT NULL, -- Тип кошелька (CUSTOMER/MERCHANT/SYSTEM)
     INDEX(user_id) -- Индексирование в таблице происходит по user_id
 );
 
 -- Создаем таблицу счет:
 CREATE TABLE account (
     id INT NOT NULL AUTO_INCREMENT PRIMARY KEY, -- Уникальный идентификатор с автоинкрементом
-    cents INT NOT NULL DEFAULT 0, -- Баланс в центах, для точности фин. расчетов
-    account_type VARCHAR(255) NOT NULL, -- Тип счета (DEFAULT/PAYMENT/INCOMING)
+    cents BIGINT NOT NULL DEFAULT 0, -- Баланс в центах, для точности фин. расчетов (отрицательный только у клиринговых счетов)
+    account_type VARCHAR(255) NOT NULL, -- Тип счета (DEFAULT/PAYMENT/INCOMING/PAYOUT_PENDING/FX/CLEARING/REVENUE)
     wallet_id INT NOT NULL, -- Внешний ключ к идентификатору кошелька
-    FOREIGN KEY (wallet_id) REFERENCES wallet(id)
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD', -- Валюта счета (код ISO 4217), баланс в ее минорных единицах
+    FOREIGN KEY (wallet_id) REFERENCES wallet(id),
+    UNIQUE(wallet_id, account_type, currency) -- У кошелька по одному счету каждого типа в каждой валюте
 );
 
 -- Создание таблицы транзакций:
@@ -34,20 +36,182 @@ CREATE TABLE transaction (
     src_account_type VARCHAR(255) NOT NULL, -- Тип аккаунта отправителя
     dst_account_type VARCHAR(255) NOT NULL, -- Тип аккаунта получателя
     final_dst_merchant_wallet_id INT, -- Опциональный идентификатор кошелька конечного продавца
-    amount INT NOT NULL, -- Сумма транзакции в центах
+    amount BIGINT NOT NULL, -- Сумма транзакции в минорных единицах валюты
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD', -- Валюта транзакции
+    fx_quote_id VARCHAR(255), -- Котировка, по которой проведена конвертация (NULL - без конвертации)
+    fx_rate DECIMAL(20,10), -- Курс конвертации с учетом спреда
+    fx_mid_rate DECIMAL(20,10), -- Рыночный курс на момент котировки
+    fx_spread_bps INT, -- Спред обменного пункта в базисных пунктах
+    memo VARCHAR(255), -- Комментарий отправителя к переводу между пользователями
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Время проведения транзакции
     INDEX(pid) -- Индексирование по идентификатору платежа для быстрого поиска
 );
 
+-- Создание таблицы платежей (явное состояние каждого платежа):
+CREATE TABLE payment (
+    pid VARCHAR(255) NOT NULL PRIMARY KEY, -- Идентификатор платежа
+    customer_wallet_id INT NOT NULL, -- Идентификатор кошелька покупателя
+    merchant_wallet_id INT NOT NULL, -- Идентификатор кошелька основного продавца (первой доли в payment_split)
+    amount BIGINT NOT NULL, -- Авторизованная сумма в центах
+    captured_amount BIGINT NOT NULL DEFAULT 0, -- Подтвержденная сумма в центах
+    refunded_amount BIGINT NOT NULL DEFAULT 0, -- Возвращенная покупателю сумма в центах
+    currency VARCHAR(3) NOT NULL, -- Валюта платежа
+    funding_amount BIGINT NOT NULL, -- Удержанная с покупателя сумма в валюте его счета
+    funding_currency VARCHAR(3) NOT NULL, -- Валюта счета покупателя (отличается от currency при конвертации)
+    fx_quote_id VARCHAR(255), -- Котировка конвертации
+    status VARCHAR(32) NOT NULL, -- Состояние (AUTHORIZED/PARTIALLY_CAPTURED/CAPTURED/VOIDED/REFUNDED/EXPIRED)
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Время авторизации
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, -- Время последнего изменения
+    expires_at TIMESTAMP NOT NULL, -- Срок действия авторизации (после него удержание снимается)
+    FOREIGN KEY (customer_wallet_id) REFERENCES wallet(id),
+    FOREIGN KEY (merchant_wallet_id) REFERENCES wallet(id),
+    INDEX(status, expires_at) -- Индекс для поиска истекших авторизаций
+);
+
+-- Создание таблицы долей продавцов в платеже (у обычного платежа одна доля на всю сумму):
+CREATE TABLE payment_split (
+    pid VARCHAR(255) NOT NULL, -- Идентификатор платежа
+    position INT NOT NULL, -- Порядковый номер доли в запросе авторизации
+    merchant_wallet_id INT NOT NULL, -- Кошелек продавца
+    amount BIGINT NOT NULL, -- Доля продавца в авторизованной сумме
+    captured_amount BIGINT NOT NULL DEFAULT 0, -- Подтвержденная часть доли
+    refunded_amount BIGINT NOT NULL DEFAULT 0, -- Возвращенная покупателю часть доли
+    PRIMARY KEY (pid, merchant_wallet_id),
+    UNIQUE(pid, position),
+    FOREIGN KEY (pid) REFERENCES payment(pid),
+    FOREIGN KEY (merchant_wallet_id) REFERENCES wallet(id)
+);
+
+-- Создание таблицы ключей идемпотентности (повтор запроса возвращает сохраненный ответ):
+CREATE TABLE idempotency_key (
+    idempotency_key VARCHAR(255) NOT NULL, -- Ключ от клиента (заголовок Idempotency-Key)
+    operation VARCHAR(32) NOT NULL, -- Операция (AUTHORIZE/CAPTURE/DEPOSIT/PAYOUT/TRANSFER)
+    fingerprint CHAR(64) NOT NULL, -- SHA-256 отпечаток запроса
+    response BLOB, -- Сохраненный ответ (protobuf)
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Время первого запроса
+    PRIMARY KEY (operation, idempotency_key)
+);
+
+-- Создание таблицы рыночных курсов валют (1 base_currency = rate quote_currency):
+CREATE TABLE fx_rate (
+    base_currency VARCHAR(3) NOT NULL, -- Базовая валюта
+    quote_currency VARCHAR(3) NOT NULL, -- Котируемая валюта
+    rate DECIMAL(20,10) NOT NULL, -- Курс
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, -- Время обновления курса
+    PRIMARY KEY (base_currency, quote_currency)
+);
+
+-- Создание таблицы котировок конвертации (курс фиксируется на срок действия котировки):
+CREATE TABLE fx_quote (
+    id VARCHAR(255) NOT NULL PRIMARY KEY, -- Идентификатор котировки
+    sell_currency VARCHAR(3) NOT NULL, -- Валюта, которую продает покупатель
+    buy_currency VARCHAR(3) NOT NULL, -- Валюта платежа
+    sell_amount BIGINT NOT NULL, -- Сумма к списанию в минорных единицах sell_currency
+    buy_amount BIGINT NOT NULL, -- Сумма платежа в минорных единицах buy_currency
+    rate DECIMAL(20,10) NOT NULL, -- Курс покупателя с учетом спреда
+    mid_rate DECIMAL(20,10) NOT NULL, -- Рыночный курс
+    spread_bps INT NOT NULL, -- Спред в базисных пунктах
+    pid VARCHAR(255), -- Платеж, использовавший котировку (NULL - не использована)
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Время выдачи котировки
+    expires_at TIMESTAMP NOT NULL -- Срок действия котировки
+);
+
+-- Создание таблицы выплат продавцам на внешние банковские счета:
+CREATE TABLE payout (
+    id VARCHAR(255) NOT NULL PRIMARY KEY, -- Идентификатор выплаты
+    merchant_wallet_id INT NOT NULL, -- Кошелек продавца
+    amount BIGINT NOT NULL, -- Сумма выплаты
+    currency VARCHAR(3) NOT NULL, -- Валюта выплаты
+    status VARCHAR(32) NOT NULL, -- Состояние (PENDING/SENT/FAILED)
+    provider_reference VARCHAR(255), -- Идентификатор выплаты у провайдера (NULL - еще не передана)
+    failure_reason VARCHAR(255), -- Причина отказа банка
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Время запроса выплаты
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, -- Время последнего изменения
+    FOREIGN KEY (merchant_wallet_id) REFERENCES wallet(id),
+    INDEX(status) -- Индекс для поиска выплат в обработке
+);
+
+-- Создание таблицы тарифных планов продавцов (комиссия платформы с каждого подтверждения платежа):
+CREATE TABLE merchant_fee_plan (
+    merchant_wallet_id INT NOT NULL, -- Кошелек продавца
+    currency VARCHAR(3) NOT NULL, -- Валюта платежа
+    percent_bps INT NOT NULL DEFAULT 0, -- Процент от суммы в базисных пунктах (1 bp = 0.01%)
+    fixed_cents BIGINT NOT NULL DEFAULT 0, -- Фиксированная часть в минорных единицах валюты
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, -- Время изменения плана
+    PRIMARY KEY (merchant_wallet_id, currency),
+    FOREIGN KEY (merchant_wallet_id) REFERENCES wallet(id)
+);
+
+-- Создание таблицы исходящих событий для Кафки (transactional outbox):
+-- событие записывается в той же транзакции, что и операция, и отправляется фоновым обработчиком по порядку id
+CREATE TABLE outbox (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY, -- Порядковый номер события
+    topic VARCHAR(255) NOT NULL, -- Топик Кафки
+    message_key VARCHAR(255) NOT NULL, -- Ключ сообщения (идентификатор операции)
+    payload BLOB NOT NULL, -- Тело сообщения
+    content_type VARCHAR(64) NOT NULL DEFAULT 'application/json', -- Заголовок content-type (формат тела)
+    attempts INT NOT NULL DEFAULT 0, -- Количество неудачных попыток отправки
+    last_error VARCHAR(1024), -- Ошибка последней попытки
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Время записи события
+    sent_at TIMESTAMP NULL, -- Время отправки (NULL - еще не отправлено)
+    INDEX(sent_at, id) -- Индекс для поиска неотправленных событий
+);
+
 -- Добавление "кошельков" продавцов и покупателей
 INSERT INTO wallet(id, user_id, wallet_type) VALUES
     (1,'sunr3d.coding@gmail.com', 'CUSTOMER'),
-    (2, 'merchant_id', 'MERCHANT');
+    (2, 'merchant_id', 'MERCHANT'),
+    (3, 'fx_desk', 'SYSTEM'), -- Обменный пункт, через который проходит конвертация валют
+    (4, 'clearing', 'SYSTEM'), -- Клиринговый кошелек, через который деньги приходят извне и уходят наружу (пополнения и выплаты)
+    (5, 'platform', 'SYSTEM'), -- Кошелек платформы, на который поступают комиссии с платежей
+    (6, 'merchant_2', 'MERCHANT'); -- Второй продавец маркетплейса
 
 -- Добавление счета покупателей
-INSERT INTO account(cents, account_type, wallet_id) VALUES
-    (5000000, 'DEFAULT', 1), -- Основной счет покупателя
-    (0, 'PAYMENT', 1); -- Счет для платежей покупателя
+INSERT INTO account(cents, account_type, wallet_id, currency) VALUES
+    (5000000, 'DEFAULT', 1, 'USD'), -- Основной счет покупателя
+    (0, 'PAYMENT', 1, 'USD'), -- Счет для платежей покупателя
+    (5000000, 'DEFAULT', 1, 'EUR'), -- Основной счет покупателя в евро
+    (0, 'PAYMENT', 1, 'EUR'), -- Счет для платежей покупателя в евро
+    (500000000, 'DEFAULT', 1, 'RUB'), -- Основной счет покупателя в рублях
+    (0, 'PAYMENT', 1, 'RUB'); -- Счет для платежей покупателя в рублях
 
 -- Добавление счета продавца
-INSERT INTO account(cents, account_type, wallet_id) VALUES
-    (0, 'INCOMING', 2); -- Счет для входяших платежей продавца
+INSERT INTO account(cents, account_type, wallet_id, currency) VALUES
+    (0, 'INCOMING', 2, 'USD'), -- Счет для входяших платежей продавца
+    (0, 'INCOMING', 2, 'EUR'), -- Счет для входяших платежей продавца в евро
+    (0, 'INCOMING', 2, 'RUB'), -- Счет для входяших платежей продавца в рублях
+    (0, 'PAYOUT_PENDING', 2, 'USD'), -- Резерв выплат продавца в банк
+    (0, 'PAYOUT_PENDING', 2, 'EUR'), -- Резерв выплат продавца в банк в евро
+    (0, 'PAYOUT_PENDING', 2, 'RUB'), -- Резерв выплат продавца в банк в рублях
+    (0, 'INCOMING', 6, 'USD'), -- Счет для входяших платежей второго продавца
+    (0, 'PAYOUT_PENDING', 6, 'USD'); -- Резерв выплат второго продавца в банк
+
+-- Добавление счетов обменного пункта (по одному на каждую валюту)
+INSERT INTO account(cents, account_type, wallet_id, currency) VALUES
+    (1000000000, 'FX', 3, 'USD'),
+    (1000000000, 'FX', 3, 'EUR'),
+    (2000000000, 'FX', 3, 'RUB');
+
+-- Добавление клиринговых счетов (счета в других валютах создаются при первом пополнении)
+INSERT INTO account(cents, account_type, wallet_id, currency) VALUES
+    (0, 'CLEARING', 4, 'USD'),
+    (0, 'CLEARING', 4, 'EUR'),
+    (0, 'CLEARING', 4, 'RUB');
+
+-- Добавление счетов доходов платформы (счета в других валютах создаются при первой комиссии)
+INSERT INTO account(cents, account_type, wallet_id, currency) VALUES
+    (0, 'REVENUE', 5, 'USD'),
+    (0, 'REVENUE', 5, 'EUR'),
+    (0, 'REVENUE', 5, 'RUB');
+
+-- Тарифные планы продавца (2.9% + фиксированная часть в каждой валюте)
+INSERT INTO merchant_fee_plan(merchant_wallet_id, currency, percent_bps, fixed_cents) VALUES
+    (2, 'USD', 290, 30),
+    (2, 'EUR', 290, 25),
+    (2, 'RUB', 290, 2000);
+
+-- Начальные курсы валют (обновляются методом SetFxRates или из файла FX_RATES_FILE)
+INSERT INTO fx_rate(base_currency, quote_currency, rate) VALUES
+    ('EUR', 'USD', 1.0850000000),
+    ('USD', 'RUB', 92.5000000000),
+    ('EUR', 'RUB', 100.3000000000);
@@ -0,0 +1,41 @@
+// Package currency содержит справочник валют ISO 4217 с количеством знаков дробной части
+package currency
+
+import (
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exponents - количество знаков дробной (минорной) единицы для валют ISO 4217.
+// Все суммы в сервисе хранятся в минорных единицах: 1 USD = 100 cents (2 знака),
+// 1 JPY = 1 (0 знаков), 1 KWD = 1000 fils (3 знака)
+var exponents = map[string]int{
+	"AED": 2, "AMD": 2, "AUD": 2, "AZN": 2, "BHD": 3, "BRL": 2, "BYN": 2, "CAD": 2,
+	"CHF": 2, "CLP": 0, "CNY": 2, "CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "GEL": 2,
+	"HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "ISK": 0, "JOD": 3, "JPY": 0,
+	"KGS": 2, "KRW": 0, "KWD": 3, "KZT": 2, "MXN": 2, "NOK": 2, "NZD": 2, "OMR": 3,
+	"PLN": 2, "RSD": 2, "RUB": 2, "SAR": 2, "SEK": 2, "SGD": 2, "THB": 2, "TND": 3,
+	"TRY": 2, "UAH": 2, "USD": 2, "UZS": 2, "VND": 0, "ZAR": 2,
+}
+
+// Validate проверяет, что code - известный трехбуквенный код валюты ISO 4217
+//
+// Возвращает:
+//   - ошибку InvalidArgument для неизвестного кода
+func Validate(code string) error {
+	if _, ok := exponents[code]; !ok {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("unsupported currency %q", code))
+	}
+	return nil
+}
+
+// Exponent возвращает количество знаков дробной части валюты code
+//
+// Возвращает:
+//   - количество знаков минорной единицы
+//   - false, если валюта неизвестна
+func Exponent(code string) (int, bool) {
+	exponent, ok := exponents[code]
+	return exponent, ok
+}
@@ -0,0 +1,186 @@
+package mm
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sunr3d/gomicro/internal/currency"
+	"github.com/sunr3d/gomicro/internal/producer"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	clearingUserID      = "clearing" // Системный кошелек, через который деньги приходят извне
+	clearingAccountType = "CLEARING" // Тип клиринговых счетов (по одному на валюту, могут уходить в минус)
+)
+
+// Deposit пополняет базовый счет покупателя
+//
+// Деньги списываются с клирингового счета системного кошелька в той же валюте,
+// поэтому сумма балансов всех счетов не меняется, а отрицательный баланс клирингового
+// счета равен сумме всех пополнений.
+//
+// Основные шаги:
+//  1. Проверка валюты и суммы
+//  2. Начало SQL транзакции и проверка ключа идемпотентности
+//  3. Получение кошелька покупателя, его базового счета и клирингового счета
+//  4. Перевод средств и создание транзакции
+//  5. Запись события о зачислении в outbox (для бухгалтерии)
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - depositPayload: пользователь, сумма и валюта пополнения
+//
+// Возвращает:
+//   - идентификатор пополнения
+//   - ошибку в случае неудачи
+func (this *Implementation) Deposit(ctx context.Context, depositPayload *pb.DepositPayload) (*pb.DepositResponse, error) {
+	// Проверка кода валюты и суммы
+	if err := currency.Validate(depositPayload.GetCurrency()); err != nil {
+		return nil, err
+	}
+	if depositPayload.GetCents() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be positive")
+	}
+
+	// Начало транзакции (включаем изолированный запрос)
+	tx, err := this.store.Begin()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Повторный запрос с тем же ключом идемпотентности не пополняет счет еще раз
+	response := &pb.DepositResponse{}
+	replayed, err := claimIdempotencyKey(tx, operationDeposit, depositPayload.GetIdempotencyKey(), depositPayload, response)
+	if err != nil || replayed {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+
+	// Получение кошелька покупателя
+	customerWallet, err := tx.fetchWallet(depositPayload.UserId)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Получение базового счета покупателя в валюте пополнения
+	dstAccount, err := tx.fetchAccount(customerWallet.ID, "DEFAULT", depositPayload.Currency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("wallet has no DEFAULT account in %s", depositPayload.Currency))
+		}
+		return nil, err
+	}
+
+	// Получение клирингового кошелька и счета
+	clearingWallet, srcAccount, err := fetchClearingAccount(tx, depositPayload.Currency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Перевод средств с клирингового счета на базовый счет покупателя
+	err = transfer(tx, srcAccount, dstAccount, depositPayload.Cents)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Создание транзакции пополнения
+	depositID := uuid.NewString()
+	err = createTransaction(
+		tx,                   // БД
+		depositID,            // айди транзакции
+		srcAccount,           // счет отправления
+		dstAccount,           // счет получения
+		clearingWallet,       // кошелек отправителя
+		customerWallet,       // кошелек получателя
+		customerWallet,       // конечный кошелек получателя
+		depositPayload.Cents) // сумма
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Сохраняем ответ для повторов запроса с тем же ключом идемпотентности
+	response.DepositId = depositID
+	err = saveIdempotencyResponse(tx, operationDeposit, depositPayload.GetIdempotencyKey(), response)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Событие о пополнении сохраняется в outbox вместе с операцией
+	messages, err := producer.DepositMessages(ctx, depositID, customerWallet.userID, depositPayload.Cents, depositPayload.Currency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	err = tx.insertOutboxMessages(messages)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Конец транзакции, коммит изменений в БД
+	err = tx.Commit()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return response, nil
+}
+
+// fetchClearingAccount возвращает клиринговый кошелек и его счет в валюте currencyCode,
+// создавая счет при первом обращении
+func fetchClearingAccount(tx UnitOfWork, currencyCode string) (wallet, account, error) {
+	return fetchSystemAccount(tx, clearingUserID, clearingAccountType, currencyCode)
+}
+
+// fetchSystemAccount возвращает системный кошелек userID и его счет accountType
+// в валюте currencyCode, создавая счет при первом обращении
+func fetchSystemAccount(tx UnitOfWork, userID string, accountType string, currencyCode string) (wallet, account, error) {
+	systemWallet, err := tx.fetchWallet(userID)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return wallet{}, account{}, status.Error(codes.FailedPrecondition, fmt.Sprintf("%s wallet is not configured", userID))
+		}
+		return wallet{}, account{}, err
+	}
+
+	err = tx.ensureAccount(systemWallet.ID, accountType, currencyCode)
+	if err != nil {
+		return wallet{}, account{}, err
+	}
+
+	systemAccount, err := tx.fetchAccount(systemWallet.ID, accountType, currencyCode)
+	if err != nil {
+		return wallet{}, account{}, err
+	}
+	return systemWallet, systemAccount, nil
+}
@@ -0,0 +1,263 @@
+package mm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sunr3d/gomicro/internal/producer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+	"time"
+)
+
+// Истекшие авторизации обрабатываются пачками, чтобы не держать долгих SQL транзакций
+const expiryBatchSize = 100
+
+// RunExpirySweeper периодически снимает удержания по истекшим авторизациям.
+// Блокирует вызывающую горутину до отмены ctx.
+//
+// Обработчик безопасно запускать на нескольких репликах сервиса одновременно:
+// каждый платеж истекает в своей SQL транзакции под блокировкой строки платежа.
+//
+// Параметры:
+//   - ctx: контекст, отмена которого останавливает обработчик
+//   - interval: интервал между проходами
+func (this *Implementation) RunExpirySweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := this.ExpireAuthorizations(ctx)
+			if err != nil {
+				log.Printf("expiry sweeper: %v\n", err)
+			}
+			if expired > 0 {
+				log.Printf("expiry sweeper: %d authorizations expired\n", expired)
+			}
+		}
+	}
+}
+
+// ExpireAuthorizations находит авторизации с истекшим сроком действия, возвращает
+// удержанные средства на базовые счета покупателей и переводит платежи в состояние EXPIRED.
+// У частично подтвержденного платежа возвращается только неподтвержденный остаток,
+// и платеж переходит в CAPTURED, как после финального подтверждения
+//
+// Ошибка одного платежа не останавливает проход: платеж пропускается до следующего прохода,
+// остальные обрабатываются.
+//
+// Возвращает:
+//   - количество истекших платежей
+//   - объединенную ошибку пропущенных платежей и ошибку выборки
+func (this *Implementation) ExpireAuthorizations(ctx context.Context) (int, error) {
+	expired := 0
+	skipped := map[string]bool{}
+	var errs []error
+	for {
+		pids, last, err := this.fetchExpiredPayments(skipped)
+		if err != nil {
+			return expired, errors.Join(append(errs, err)...)
+		}
+
+		for _, pid := range pids {
+			ok, err := this.expirePayment(ctx, pid)
+			if err != nil {
+				log.Printf("expiry sweeper: payment %s skipped: %v\n", pid, err)
+				skipped[pid] = true
+				errs = append(errs, fmt.Errorf("payment %s: %w", pid, err))
+				continue
+			}
+			if ok {
+				expired++
+			}
+		}
+
+		if last || ctx.Err() != nil {
+			return expired, errors.Join(errs...)
+		}
+	}
+}
+
+// fetchExpiredPayments возвращает очередную пачку истекших авторизаций без пропущенных в этом проходе.
+// Пропущенные платежи остаются в выборке, поэтому она расширяется на их количество
+//
+// Возвращает:
+//   - идентификаторы платежей
+//   - true, если это последняя пачка
+//   - ошибку в случае неудачи
+func (this *Implementation) fetchExpiredPayments(skipped map[string]bool) ([]string, bool, error) {
+	tx, err := this.store.Begin()
+	if err != nil {
+		return nil, false, status.Error(codes.Internal, err.Error())
+	}
+	defer func() {
+		// Выборка только читает данные, поэтому всегда откатывается
+		_ = tx.Rollback()
+	}()
+
+	limit := expiryBatchSize + len(skipped)
+	fetched, err := tx.fetchExpiredPayments(limit)
+	if err != nil {
+		return nil, false, err
+	}
+	pids := make([]string, 0, len(fetched))
+	for _, pid := range fetched {
+		if !skipped[pid] {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, len(fetched) < limit, nil
+}
+
+// expirePayment снимает удержание по одному платежу
+//
+// Основные шаги:
+//  1. Начало SQL транзакции
+//  2. Получение платежа с блокировкой и проверка перехода по истечению срока
+//  3. Перевод неподтвержденного остатка (если он не нулевой) с расчетного счета на базовый счет покупателя
+//  4. Создание транзакции возврата и перевод платежа в состояние EXPIRED (CAPTURED, если часть подтверждена)
+//  5. Запись события об истечении авторизации в outbox
+//
+// Возвращает:
+//   - false, если платеж уже обработан (подтвержден, отменен или истек на другой реплике)
+//   - ошибку в случае неудачи
+func (this *Implementation) expirePayment(ctx context.Context, pid string) (bool, error) {
+	// Начало транзакции (включаем изолированный запрос)
+	tx, err := this.store.Begin()
+	if err != nil {
+		return false, status.Error(codes.Internal, err.Error())
+	}
+
+	// Получение платежа по его pid с блокировкой строки до конца транзакции
+	payment, err := tx.lockPayment(pid)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+
+	// Платеж мог измениться после выборки истекших авторизаций
+	nextStatus, err := nextPaymentStatus(payment.status, eventExpire)
+	if err != nil || !payment.expired {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, nil
+	}
+
+	// Получение информации о расчетном и базовом счетах покупателя
+	srcAccount, err := tx.fetchAccount(payment.customerWalletID, "PAYMENT", payment.fundingCurrency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+	dstAccount, err := tx.fetchAccount(payment.customerWalletID, "DEFAULT", payment.fundingCurrency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+
+	// Получение кошельков покупателя и продавца
+	customerWallet, err := tx.fetchWalletWithWalletID(payment.customerWalletID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+	merchantWallet, err := tx.fetchWalletWithWalletID(payment.merchantWalletID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+
+	// Возврат удержанных средств с расчетного счета на базовый. При конвертации
+	// неподтвержденный остаток может округлиться до нуля: тогда меняется только состояние
+	releaseAmount := payment.fundingAmount - fundingShare(payment, payment.capturedAmount)
+	if releaseAmount > 0 {
+		err = transfer(tx, srcAccount, dstAccount, releaseAmount)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return false, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return false, err
+		}
+
+		// Создание транзакции возврата удержания
+		err = createTransaction(
+			tx,             // БД
+			payment.pid,    // айди транзакции
+			srcAccount,     // счет отправления
+			dstAccount,     // счет получения
+			customerWallet, // кошелек отправителя
+			customerWallet, // кошелек получателя
+			merchantWallet, // конечный кошелек получателя
+			releaseAmount)  // сумма
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return false, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return false, err
+		}
+	}
+
+	// Перевод платежа в состояние EXPIRED или CAPTURED
+	payment.status = nextStatus
+	err = tx.updatePayment(payment)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+
+	// Событие об истечении авторизации: продавцы теряют неподтвержденные части долей (outbox)
+	splits, err := tx.fetchPaymentSplits(payment.pid)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+	shares, err := splitShares(tx, splits, func(s paymentSplit) int64 { return s.amount - s.capturedAmount })
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+	messages, err := producer.PaymentExpiredMessages(ctx, newPaymentEvent(payment, customerWallet, shares))
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, status.Error(codes.Internal, err.Error())
+	}
+	err = tx.insertOutboxMessages(messages)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+
+	// Запись транзакции в БД
+	err = tx.Commit()
+	if err != nil {
+		return false, status.Error(codes.Internal, err.Error())
+	}
+
+	return true, nil
+}
@@ -0,0 +1,66 @@
+package mm
+
+const (
+	platformUserID     = "platform" // Системный кошелек платформы, на который поступают комиссии
+	revenueAccountType = "REVENUE"  // Тип счетов доходов платформы (по одному на валюту)
+)
+
+// feePlan - тарифный план продавца: процент от суммы и фиксированная часть за каждое подтверждение
+type feePlan struct {
+	percentBps int32 // Процент в базисных пунктах (1 bp = 0.01%)
+	fixedCents int64 // Фиксированная комиссия в минорных единицах валюты
+}
+
+// fee возвращает комиссию платформы с подтверждаемой суммы amount.
+// Процентная часть округляется до ближайшего целого (половина - вверх),
+// комиссия не может превышать сумму подтверждения
+func (this feePlan) fee(amount int64) int64 {
+	fee := (amount*int64(this.percentBps)+5000)/10000 + this.fixedCents
+	return min(fee, amount)
+}
+
+// fetchRevenueAccount возвращает кошелек платформы и его счет доходов в валюте currencyCode,
+// создавая счет при первом обращении
+func fetchRevenueAccount(tx UnitOfWork, currencyCode string) (wallet, account, error) {
+	return fetchSystemAccount(tx, platformUserID, revenueAccountType, currencyCode)
+}
+
+// isFeeTransaction сообщает, является ли транзакция удержанием комиссии платформы (INCOMING -> REVENUE)
+func isFeeTransaction(t transaction) bool {
+	return t.srcAccountType == "INCOMING" && t.dstAccountType == revenueAccountType
+}
+
+// isFeeRefundTransaction сообщает, является ли транзакция возвратом комиссии продавцу при возврате платежа (REVENUE -> INCOMING)
+func isFeeRefundTransaction(t transaction) bool {
+	return t.srcAccountType == revenueAccountType && t.dstAccountType == "INCOMING"
+}
+
+// refundFee возвращает часть комиссии, удержанной с доли продавца split, которую платформа
+// возвращает продавцу при возврате покупателю amount центов.
+// Комиссии доли считаются по транзакциям платежа transactions
+//
+// Возвращается доля комиссии, пропорциональная доле возврата в подтвержденной сумме.
+// Последний возврат доли забирает весь остаток комиссии, поэтому округления не накапливаются
+func refundFee(transactions []transaction, split paymentSplit, amount int64) int64 {
+	var charged, returned int64
+	for _, t := range transactions {
+		if t.finalDstMerchantWalletID != split.merchantWalletID {
+			continue
+		}
+		switch {
+		case isFeeTransaction(t):
+			charged += t.amount
+		case isFeeRefundTransaction(t):
+			returned += t.amount
+		}
+	}
+
+	remaining := charged - returned
+	if remaining <= 0 || split.capturedAmount == 0 {
+		return 0
+	}
+	if amount == split.capturedAmount-split.refundedAmount {
+		return remaining
+	}
+	return min(charged*amount/split.capturedAmount, remaining)
+}
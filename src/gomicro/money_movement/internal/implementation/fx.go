@@ -0,0 +1,445 @@
+package mm
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sunr3d/gomicro/internal/currency"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"math"
+	"math/big"
+	"os"
+)
+
+const (
+	fxDeskUserID      = "fx_desk" // Системный кошелек обменного пункта
+	fxAccountType     = "FX"      // Тип счетов обменного пункта (по одному на валюту)
+	fxRateScale       = 10        // Количество знаков после запятой при хранении курсов
+	basisPointsInUnit = 10000
+)
+
+// fxDesk - счета обменного пункта, через которые проходит конвертация из одной валюты в другую
+type fxDesk struct {
+	wallet     wallet
+	srcAccount account // Счет в продаваемой покупателем валюте
+	dstAccount account // Счет в покупаемой валюте
+}
+
+// CreateFxQuote выдает котировку с зафиксированным курсом конвертации
+//
+// Курс покупателя равен рыночному курсу из таблицы fx_rate за вычетом спреда.
+// Котировка действует fxQuoteTTL и может быть использована одним вызовом Authorize.
+//
+// Основные шаги:
+//  1. Проверка валют и суммы
+//  2. Получение рыночного курса и счетов обменного пункта
+//  3. Расчет курса со спредом и суммы к списанию
+//  4. Сохранение котировки
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - quotePayload: валютная пара и сумма в покупаемой валюте
+//
+// Возвращает:
+//   - котировку
+//   - ошибку в случае неудачи
+func (this *Implementation) CreateFxQuote(ctx context.Context, quotePayload *pb.FxQuotePayload) (*pb.FxQuote, error) {
+	// Проверка валютной пары и суммы
+	if err := currency.Validate(quotePayload.GetSellCurrency()); err != nil {
+		return nil, err
+	}
+	if err := currency.Validate(quotePayload.GetBuyCurrency()); err != nil {
+		return nil, err
+	}
+	if quotePayload.GetSellCurrency() == quotePayload.GetBuyCurrency() {
+		return nil, status.Error(codes.InvalidArgument, "sell and buy currencies must differ")
+	}
+	if quotePayload.GetBuyCents() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be positive")
+	}
+
+	// Начало транзакции (включаем изолированный запрос)
+	tx, err := this.store.Begin()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Получение рыночного курса валютной пары
+	midRate, err := fetchFxRate(tx, quotePayload.SellCurrency, quotePayload.BuyCurrency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Обменный пункт должен держать счета в обеих валютах
+	_, err = fetchFxDesk(tx, quotePayload.SellCurrency, quotePayload.BuyCurrency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Курс покупателя со спредом и сумма к списанию в продаваемой валюте
+	rate := applySpread(midRate, this.fxSpreadBps)
+	sellAmount, err := sellAmountFor(quotePayload.BuyCents, quotePayload.SellCurrency, quotePayload.BuyCurrency, rate)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	quote := fxQuote{
+		ID:           uuid.NewString(),
+		sellCurrency: quotePayload.SellCurrency,
+		buyCurrency:  quotePayload.BuyCurrency,
+		sellAmount:   sellAmount,
+		buyAmount:    quotePayload.BuyCents,
+		rate:         rate.FloatString(fxRateScale),
+		midRate:      midRate.FloatString(fxRateScale),
+		spreadBps:    this.fxSpreadBps,
+	}
+
+	// Сохранение котировки
+	err = tx.createFxQuote(quote, this.fxQuoteTTL)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Срок действия считается по часам хранилища, поэтому читаем котировку обратно
+	quote, err = tx.fetchFxQuote(quote.ID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Конец транзакции, коммит изменений в БД
+	err = tx.Commit()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.FxQuote{
+		QuoteId:      quote.ID,
+		SellCurrency: quote.sellCurrency,
+		BuyCurrency:  quote.buyCurrency,
+		SellCents:    quote.sellAmount,
+		BuyCents:     quote.buyAmount,
+		Rate:         quote.rate,
+		MidRate:      quote.midRate,
+		SpreadBps:    quote.spreadBps,
+		ExpiresAt:    timestamppb.New(quote.expiresAt),
+	}, nil
+}
+
+// SetFxRates добавляет или обновляет рыночные курсы валют.
+// Все курсы из запроса сохраняются в одной SQL транзакции
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - ratesPayload: список курсов
+//
+// Возвращает:
+//   - пустой ответ
+//   - ошибку в случае неудачи
+func (this *Implementation) SetFxRates(ctx context.Context, ratesPayload *pb.SetFxRatesPayload) (*emptypb.Empty, error) {
+	if len(ratesPayload.GetRates()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no rates provided")
+	}
+
+	// Проверка всех курсов до записи в БД
+	rates := make([]*big.Rat, 0, len(ratesPayload.Rates))
+	for _, r := range ratesPayload.Rates {
+		if err := currency.Validate(r.GetBaseCurrency()); err != nil {
+			return nil, err
+		}
+		if err := currency.Validate(r.GetQuoteCurrency()); err != nil {
+			return nil, err
+		}
+		if r.BaseCurrency == r.QuoteCurrency {
+			return nil, status.Error(codes.InvalidArgument, "base and quote currencies must differ")
+		}
+		rate, err := parseFxRate(r.GetRate())
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	// Начало транзакции (включаем изолированный запрос)
+	tx, err := this.store.Begin()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	for i, r := range ratesPayload.Rates {
+		err = tx.upsertFxRate(r.BaseCurrency, r.QuoteCurrency, rates[i].FloatString(fxRateScale))
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
+	}
+
+	// Конец транзакции, коммит изменений в БД
+	err = tx.Commit()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// LoadFxRatesFile загружает курсы валют из JSON файла вида
+//
+//	[{"base_currency": "EUR", "quote_currency": "USD", "rate": "1.0845"}]
+//
+// Используется при старте сервиса, дальше курсы обновляются через SetFxRates
+func (this *Implementation) LoadFxRatesFile(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var rates []struct {
+		BaseCurrency  string `json:"base_currency"`
+		QuoteCurrency string `json:"quote_currency"`
+		Rate          string `json:"rate"`
+	}
+	if err = json.Unmarshal(data, &rates); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	payload := &pb.SetFxRatesPayload{}
+	for _, r := range rates {
+		payload.Rates = append(payload.Rates, &pb.FxRate{
+			BaseCurrency:  r.BaseCurrency,
+			QuoteCurrency: r.QuoteCurrency,
+			Rate:          r.Rate,
+		})
+	}
+
+	_, err = this.SetFxRates(ctx, payload)
+	return err
+}
+
+// fetchFxRate возвращает рыночный курс: единиц quoteCurrency за единицу baseCurrency.
+// Если в таблице есть только обратная пара, курс инвертируется
+func fetchFxRate(tx UnitOfWork, baseCurrency string, quoteCurrency string) (*big.Rat, error) {
+	value, err := tx.fetchFxRateValue(baseCurrency, quoteCurrency)
+	if err == nil {
+		return parseFxRate(value)
+	}
+	if status.Code(err) != codes.NotFound {
+		return nil, err
+	}
+
+	// Обратная пара
+	value, err = tx.fetchFxRateValue(quoteCurrency, baseCurrency)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("no fx rate for %s/%s", baseCurrency, quoteCurrency))
+		}
+		return nil, err
+	}
+	rate, err := parseFxRate(value)
+	if err != nil {
+		return nil, err
+	}
+	return rate.Inv(rate), nil
+}
+
+// fetchFxDesk возвращает кошелек обменного пункта и его счета в валютах конвертации
+func fetchFxDesk(tx UnitOfWork, srcCurrency string, dstCurrency string) (fxDesk, error) {
+	var desk fxDesk
+
+	deskWallet, err := tx.fetchWallet(fxDeskUserID)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return desk, status.Error(codes.FailedPrecondition, "fx desk is not configured")
+		}
+		return desk, err
+	}
+	desk.wallet = deskWallet
+
+	for _, a := range []struct {
+		currency string
+		account  *account
+	}{
+		{srcCurrency, &desk.srcAccount},
+		{dstCurrency, &desk.dstAccount},
+	} {
+		*a.account, err = tx.fetchAccount(deskWallet.ID, fxAccountType, a.currency)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return desk, status.Error(codes.FailedPrecondition, fmt.Sprintf("fx desk does not trade %s", a.currency))
+			}
+			return desk, err
+		}
+	}
+	return desk, nil
+}
+
+// useFxQuote блокирует котировку и закрепляет ее за платежом pid
+//
+// Возвращает ошибку FailedPrecondition, если котировка истекла или уже использована,
+// и InvalidArgument, если сумма или валюта платежа не совпадают с котировкой
+func useFxQuote(tx UnitOfWork, id string, pid string, cents int64, paymentCurrency string) (fxQuote, error) {
+	q, err := tx.lockFxQuote(id)
+	if err != nil {
+		return q, err
+	}
+	if q.pid.Valid {
+		return q, status.Error(codes.FailedPrecondition, "fx quote already used")
+	}
+	if q.expired {
+		return q, status.Error(codes.FailedPrecondition, "fx quote expired")
+	}
+	if q.buyCurrency != paymentCurrency || q.buyAmount != cents {
+		return q, status.Error(codes.InvalidArgument, "payment does not match fx quote")
+	}
+
+	err = tx.setFxQuotePid(q.ID, pid)
+	if err != nil {
+		return q, err
+	}
+	q.pid = sql.NullString{String: pid, Valid: true}
+	return q, nil
+}
+
+// exchange конвертирует средства через обменный пункт двумя переводами:
+// srcAmount со счета srcAccount на счет обменного пункта в той же валюте
+// и dstAmount со счета обменного пункта в валюте dstAccount на dstAccount.
+// Обе транзакции записываются с курсом и спредом котировки quote
+func exchange(tx UnitOfWork, pid string, desk fxDesk, quote fxQuote, srcAccount account, dstAccount account, srcWallet wallet, dstWallet wallet, finalDstWallet wallet, srcAmount int64, dstAmount int64) error {
+	// Все четыре счета блокируются сразу и в едином порядке
+	err := tx.lockAccounts(srcAccount, dstAccount, desk.srcAccount, desk.dstAccount)
+	if err != nil {
+		return err
+	}
+
+	// Продажа валюты покупателя обменному пункту
+	err = transfer(tx, srcAccount, desk.srcAccount, srcAmount)
+	if err != nil {
+		return err
+	}
+	err = createFxTransaction(tx, pid, srcAccount, desk.srcAccount, srcWallet, desk.wallet, finalDstWallet, srcAmount, quote)
+	if err != nil {
+		return err
+	}
+
+	// Выплата купленной валюты получателю
+	err = transfer(tx, desk.dstAccount, dstAccount, dstAmount)
+	if err != nil {
+		return err
+	}
+	return createFxTransaction(tx, pid, desk.dstAccount, dstAccount, desk.wallet, dstWallet, finalDstWallet, dstAmount, quote)
+}
+
+// parseFxRate разбирает десятичную строку курса. Курс должен быть положительным
+func parseFxRate(value string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid fx rate %q", value))
+	}
+	return rate, nil
+}
+
+// applySpread возвращает курс покупателя: рыночный курс, уменьшенный на spreadBps базисных пунктов
+func applySpread(midRate *big.Rat, spreadBps int32) *big.Rat {
+	factor := big.NewRat(int64(basisPointsInUnit-spreadBps), basisPointsInUnit)
+	rate := new(big.Rat).Mul(midRate, factor)
+	// Курс хранится с точностью fxRateScale знаков, расчеты ведутся по сохраненному значению
+	rate, _ = new(big.Rat).SetString(rate.FloatString(fxRateScale))
+	return rate
+}
+
+// sellAmountFor считает, сколько минорных единиц sellCurrency нужно продать по курсу rate,
+// чтобы получить buyAmount минорных единиц buyCurrency. Результат округляется вверх
+func sellAmountFor(buyAmount int64, sellCurrency string, buyCurrency string, rate *big.Rat) (int64, error) {
+	sellExponent, _ := currency.Exponent(sellCurrency)
+	buyExponent, _ := currency.Exponent(buyCurrency)
+
+	// sell = buy / 10^buyExponent / rate * 10^sellExponent
+	amount := new(big.Rat).SetInt64(buyAmount)
+	amount.Mul(amount, new(big.Rat).SetInt(pow10(sellExponent)))
+	amount.Quo(amount, new(big.Rat).SetInt(pow10(buyExponent)))
+	amount.Quo(amount, rate)
+
+	sell, remainder := new(big.Int).QuoRem(amount.Num(), amount.Denom(), new(big.Int))
+	if remainder.Sign() > 0 {
+		sell.Add(sell, big.NewInt(1))
+	}
+	if !sell.IsInt64() || sell.Int64() > math.MaxInt32 {
+		return 0, status.Error(codes.InvalidArgument, "amount is too large")
+	}
+	return sell.Int64(), nil
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
+
+// fundingShare возвращает часть удержанной с покупателя суммы (в валюте его счета),
+// соответствующую сумме amount в валюте платежа. Для платежей без конвертации равна amount.
+//
+// Доля считается от накопленной суммы, поэтому при нескольких частичных операциях
+// ошибки округления не накапливаются: fundingShare(p, p.amount) == p.fundingAmount
+func fundingShare(p payment, amount int64) int64 {
+	if amount == p.amount {
+		return p.fundingAmount
+	}
+	// Округление до ближайшего целого (половина - вверх): (2*funding*amount + total) / (2*total).
+	// Произведение считается в big.Int, так как при крупных суммах не помещается в int64
+	share := new(big.Int).Mul(big.NewInt(2*p.fundingAmount), big.NewInt(amount))
+	share.Add(share, big.NewInt(p.amount))
+	share.Quo(share, new(big.Int).Mul(big.NewInt(2), big.NewInt(p.amount)))
+	return share.Int64()
+}
+
+// settle переводит средства между счетами покупателя и продавца. Если валюты счетов
+// различаются, перевод идет через обменный пункт по курсу котировки платежа
+func settle(tx UnitOfWork, p payment, desk fxDesk, quote fxQuote, srcAccount account, dstAccount account, srcWallet wallet, dstWallet wallet, finalDstWallet wallet, srcAmount int64, dstAmount int64) error {
+	if srcAccount.currency != dstAccount.currency {
+		return exchange(tx, p.pid, desk, quote, srcAccount, dstAccount, srcWallet, dstWallet, finalDstWallet, srcAmount, dstAmount)
+	}
+
+	err := transfer(tx, srcAccount, dstAccount, dstAmount)
+	if err != nil {
+		return err
+	}
+	return createTransaction(tx, p.pid, srcAccount, dstAccount, srcWallet, dstWallet, finalDstWallet, dstAmount)
+}
+
+// fetchPaymentFx возвращает котировку и счета обменного пункта для платежа с конвертацией.
+// Для платежей без конвертации возвращает нулевые значения
+func fetchPaymentFx(tx UnitOfWork, p payment, srcCurrency string, dstCurrency string) (fxDesk, fxQuote, error) {
+	if !p.fxQuoteID.Valid {
+		return fxDesk{}, fxQuote{}, nil
+	}
+
+	quote, err := tx.fetchFxQuote(p.fxQuoteID.String)
+	if err != nil {
+		return fxDesk{}, fxQuote{}, err
+	}
+	desk, err := fetchFxDesk(tx, srcCurrency, dstCurrency)
+	if err != nil {
+		return fxDesk{}, fxQuote{}, err
+	}
+	return desk, quote, nil
+}
@@ -0,0 +1,99 @@
+package mm
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestSellAmountFor(t *testing.T) {
+	tests := []struct {
+		name         string
+		buyAmount    int64
+		sellCurrency string
+		buyCurrency  string
+		rate         string // Единиц buyCurrency за единицу sellCurrency
+		want         int64
+	}{
+		{name: "exact", buyAmount: 900, sellCurrency: "USD", buyCurrency: "EUR", rate: "0.9", want: 1000},
+		{name: "rounded up", buyAmount: 1000, sellCurrency: "USD", buyCurrency: "EUR", rate: "0.9", want: 1112},
+		{name: "one minor unit", buyAmount: 1, sellCurrency: "USD", buyCurrency: "EUR", rate: "1000", want: 1},
+		{name: "sell without minor units", buyAmount: 100, sellCurrency: "JPY", buyCurrency: "USD", rate: "0.0067", want: 150},
+		{name: "sell with three digits", buyAmount: 325, sellCurrency: "KWD", buyCurrency: "USD", rate: "3.25", want: 1000},
+		{name: "largest amount", buyAmount: math.MaxInt32, sellCurrency: "USD", buyCurrency: "EUR", rate: "1", want: math.MaxInt32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, _ := new(big.Rat).SetString(tt.rate)
+			got, err := sellAmountFor(tt.buyAmount, tt.sellCurrency, tt.buyCurrency, rate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("sellAmountFor = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSellAmountForTooLarge(t *testing.T) {
+	tests := []struct {
+		name      string
+		buyAmount int64
+		rate      string
+	}{
+		{name: "above MaxInt32", buyAmount: math.MaxInt32, rate: "0.5"},
+		{name: "rounded above MaxInt32", buyAmount: math.MaxInt32, rate: "0.9999999999"},
+		{name: "above MaxInt64", buyAmount: math.MaxInt64, rate: "0.0001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, _ := new(big.Rat).SetString(tt.rate)
+			_, err := sellAmountFor(tt.buyAmount, "USD", "EUR", rate)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFundingShare(t *testing.T) {
+	tests := []struct {
+		name    string
+		payment payment
+		amount  int64
+		want    int64
+	}{
+		{name: "no conversion", payment: payment{amount: 1000, fundingAmount: 1000}, amount: 250, want: 250},
+		{name: "rounded down", payment: payment{amount: 3, fundingAmount: 10}, amount: 1, want: 3},
+		{name: "rounded up", payment: payment{amount: 3, fundingAmount: 10}, amount: 2, want: 7},
+		{name: "half rounded up", payment: payment{amount: 4, fundingAmount: 2}, amount: 1, want: 1},
+		{name: "whole amount", payment: payment{amount: 3, fundingAmount: 10}, amount: 3, want: 10},
+		{name: "rounded to zero", payment: payment{amount: 1000, fundingAmount: 1}, amount: 1, want: 0},
+		{name: "large amounts", payment: payment{amount: 4 * math.MaxInt32, fundingAmount: math.MaxInt32}, amount: 2 * math.MaxInt32, want: (math.MaxInt32 + 1) / 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fundingShare(tt.payment, tt.amount); got != tt.want {
+				t.Errorf("fundingShare = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFundingShareAccumulated(t *testing.T) {
+	// Доли считаются от накопленной суммы: сумма частей равна удержанию целиком
+	p := payment{amount: 7, fundingAmount: 10}
+	total := int64(0)
+	captured := int64(0)
+	for range p.amount {
+		share := fundingShare(p, captured+1) - fundingShare(p, captured)
+		captured++
+		total += share
+	}
+	if total != p.fundingAmount {
+		t.Errorf("sum of shares = %d, want %d", total, p.fundingAmount)
+	}
+}
@@ -0,0 +1,103 @@
+package mm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Операции, для которых поддерживаются ключи идемпотентности
+const (
+	operationAuthorize = "AUTHORIZE"
+	operationCapture   = "CAPTURE"
+	operationDeposit   = "DEPOSIT"
+	operationPayout    = "PAYOUT"
+	operationTransfer  = "TRANSFER"
+)
+
+const idempotencyKeyFieldName = "idempotency_key" // Поле запроса, не участвующее в отпечатке
+
+// claimIdempotencyKey закрепляет ключ идемпотентности за запросом внутри единицы работы
+//
+// Если ключ пустой, запрос выполняется без идемпотентности.
+// Если ключ новый, он сохраняется вместе с отпечатком запроса: параллельный запрос
+// с тем же ключом будет ждать завершения этой транзакции на уникальном индексе.
+// Если ключ уже использован с тем же отпечатком, сохраненный ответ записывается
+// в response и возвращается replayed = true. Другой отпечаток - ошибка InvalidArgument.
+//
+// Параметры:
+//   - tx: единица работы операции
+//   - operation: операция, к которой относится ключ (AUTHORIZE/CAPTURE/DEPOSIT/PAYOUT)
+//   - key: ключ идемпотентности от клиента
+//   - request: запрос клиента, по которому считается отпечаток
+//   - response: ответ, в который будет распакован сохраненный результат
+//
+// Возвращает:
+//   - признак того, что запрос уже выполнялся и response заполнен
+//   - ошибку в случае неудачи
+func claimIdempotencyKey(tx UnitOfWork, operation string, key string, request proto.Message, response proto.Message) (bool, error) {
+	if key == "" {
+		return false, nil
+	}
+
+	fingerprint, err := requestFingerprint(request)
+	if err != nil {
+		return false, err
+	}
+
+	inserted, err := tx.insertIdempotencyKey(operation, key, fingerprint)
+	if err != nil || inserted {
+		return false, err
+	}
+
+	// Ключ уже использован: сравниваем отпечатки и возвращаем сохраненный ответ
+	storedFingerprint, storedResponse, err := tx.fetchIdempotencyKey(operation, key)
+	if err != nil {
+		return false, err
+	}
+	if storedFingerprint != fingerprint {
+		return false, status.Error(codes.InvalidArgument, "idempotency key already used with a different payload")
+	}
+	if !storedResponse.Valid {
+		return false, status.Error(codes.Aborted, "request with this idempotency key is in progress")
+	}
+
+	if err = proto.Unmarshal(storedResponse.V, response); err != nil {
+		return false, status.Error(codes.Internal, err.Error())
+	}
+	return true, nil
+}
+
+// saveIdempotencyResponse сохраняет ответ на запрос с ключом идемпотентности.
+// Вызывается в той же SQL транзакции перед коммитом, поэтому ответ сохраняется
+// только вместе с результатом успешной операции
+func saveIdempotencyResponse(tx UnitOfWork, operation string, key string, response proto.Message) error {
+	if key == "" {
+		return nil
+	}
+
+	data, err := proto.Marshal(response)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return tx.updateIdempotencyResponse(operation, key, data)
+}
+
+// requestFingerprint считает SHA-256 отпечаток запроса без учета самого ключа идемпотентности
+func requestFingerprint(request proto.Message) (string, error) {
+	m := proto.Clone(request).ProtoReflect()
+	if fd := m.Descriptor().Fields().ByName(idempotencyKeyFieldName); fd != nil {
+		m.Clear(fd)
+	}
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(m.Interface())
+	if err != nil {
+		return "", status.Error(codes.Internal, err.Error())
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -3,46 +3,65 @@ package mm
 import (
 	"context"
 	"database/sql"
-	"errors"
+	"fmt"
 	"github.com/google/uuid" // Пакет для работы со стрингами вида ID
+	"github.com/sunr3d/gomicro/internal/currency"
+	"github.com/sunr3d/gomicro/internal/payout"
 	"github.com/sunr3d/gomicro/internal/producer"
 	pb "github.com/sunr3d/gomicro/proto"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
-)
-
-const (
-	insertTransactionQuery = "INSERT INTO transaction (pid, src_user_id, dst_user_id, src_wallet_id, dst_wallet_id, src_account_type, dst_account_type, final_dst_merchant_wallet_id, amount) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
-	selectTransactionQuery = "SELECT id, pid, src_user_id, dst_user_id, src_wallet_id, dst_wallet_id, src_account_type, dst_account_type, final_dst_merchant_wallet_id, amount FROM transaction WHERE pid = ?"
+	"time"
 )
 
 // Implementation представляет сервис перемещения денежных средств.
 // Реализует интерфейс MoneyMovementServiceServer
 type Implementation struct {
-	db *sql.DB
+	store            Store
+	authorizationTTL time.Duration // Срок действия авторизации до ее автоматического истечения
+	fxQuoteTTL       time.Duration // Срок действия котировки конвертации
+	fxSpreadBps      int32         // Спред обменного пункта в базисных пунктах
+	payoutProvider   payout.Provider
+	publisher        producer.Publisher // Отправка событий из outbox в Кафку
 	pb.UnimplementedMoneyMovementServiceServer
 }
 
 // NewMoneyMovementImplementation создает новый экземпляр сервиса перемещения денег
 //
 // Параметры:
-//   - db: подключение к базе данных
+//   - store: хранилище данных (NewMySQLStore или NewMemoryStore)
+//   - authorizationTTL: срок действия авторизации, после которого удержание снимается
+//   - fxQuoteTTL: срок действия котировки конвертации
+//   - fxSpreadBps: спред обменного пункта в базисных пунктах (1 bp = 0.01%)
+//   - payoutProvider: провайдер выплат продавцам во внешний банк
+//   - publisher: продюсер Кафки для событий из outbox (закрывается вызывающим)
 //
 // Возвращает:
 //   - указатель на новый экземпляр Implementation
-func NewMoneyMovementImplementation(db *sql.DB) *Implementation {
-	return &Implementation{db: db}
+func NewMoneyMovementImplementation(store Store, authorizationTTL time.Duration, fxQuoteTTL time.Duration, fxSpreadBps int32, payoutProvider payout.Provider, publisher producer.Publisher) *Implementation {
+	return &Implementation{
+		store:            store,
+		authorizationTTL: authorizationTTL,
+		fxQuoteTTL:       fxQuoteTTL,
+		fxSpreadBps:      fxSpreadBps,
+		payoutProvider:   payoutProvider,
+		publisher:        publisher,
+	}
 }
 
 // Authorize выполняет авторизацию платежа
 //
 // Основные шаги:
-//  1. Проверка валюты
+//  1. Проверка валюты и суммы
 //  2. Начало SQL транзакции
-//  3. Получение кошельков покупателя и продавца
-//  4. Перевод средств между счетами
-//  5. Создание транзакции
+//  3. Проверка ключа идемпотентности (повтор запроса возвращает исходный pid)
+//  4. Получение котировки, если платеж оплачивается со счета в другой валюте
+//  5. Получение кошельков покупателя и продавцов и их счетов
+//  6. Перевод средств между счетами покупателя
+//  7. Создание транзакции
+//  8. Создание платежа в состоянии AUTHORIZED и долей продавцов
+//  9. Запись события об авторизации в outbox
 //
 // Параметры:
 //   - ctx: контекст выполнения
@@ -52,28 +71,75 @@ func NewMoneyMovementImplementation(db *sql.DB) *Implementation {
 //   - идентификатор транзакции
 //   - ошибку в случае неудачи
 func (this *Implementation) Authorize(ctx context.Context, authorizePayload *pb.AuthorizePayload) (*pb.AuthorizeResponse, error) {
-	// Проверка поддерживаемой валюты
-	if authorizePayload.GetCurrency() != "USD" {
-		return nil, status.Error(codes.InvalidArgument, "only accepts USD")
+	// Проверка кода валюты по справочнику ISO 4217
+	if err := currency.Validate(authorizePayload.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	// Проверка суммы платежа
+	if authorizePayload.GetCents() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be positive")
 	}
 
 	// Начало транзакции (включаем изолированный запрос)
-	tx, err := this.db.Begin()
+	tx, err := this.store.Begin()
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	// Получаем айди кошелька продавца
-	merchantWallet, err := fetchWallet(tx, authorizePayload.MerchantWalletUserID)
+	// Повторный запрос с тем же ключом идемпотентности возвращает исходный результат
+	response := &pb.AuthorizeResponse{}
+	replayed, err := claimIdempotencyKey(tx, operationAuthorize, authorizePayload.GetIdempotencyKey(), authorizePayload, response)
+	if err != nil || replayed {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+
+	// Создаем айди транзакции для дальнейшей работы с ней
+	pid := uuid.NewString()
+
+	// Без котировки платеж оплачивается со счета в валюте платежа
+	p := payment{
+		pid:             pid,
+		amount:          authorizePayload.Cents,
+		currency:        authorizePayload.Currency,
+		fundingAmount:   authorizePayload.Cents,
+		fundingCurrency: authorizePayload.Currency,
+	}
+
+	// С котировкой удерживается сумма в продаваемой валюте, курс фиксируется за платежом
+	var quote fxQuote
+	if authorizePayload.GetFxQuoteId() != "" {
+		quote, err = useFxQuote(tx, authorizePayload.FxQuoteId, pid, authorizePayload.Cents, authorizePayload.Currency)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
+		p.fundingAmount = quote.sellAmount
+		p.fundingCurrency = quote.sellCurrency
+		p.fxQuoteID = sql.NullString{String: quote.ID, Valid: true}
+	}
+
+	// Получаем доли и кошельки продавцов (у обычного платежа - один продавец на всю сумму).
+	// Первый продавец считается основным продавцом платежа
+	splits, merchantWallets, err := resolveSplits(tx, authorizePayload)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return nil, status.Error(codes.Internal, rollbackErr.Error())
 		}
 		return nil, err
 	}
+	merchantWallet := merchantWallets[0]
 
 	// Получаем айди кошелька покупателя
-	customerWallet, err := fetchWallet(tx, authorizePayload.CustomerWalletUserID)
+	customerWallet, err := tx.fetchWallet(authorizePayload.CustomerWalletUserID)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return nil, status.Error(codes.Internal, rollbackErr.Error())
@@ -82,7 +148,7 @@ func (this *Implementation) Authorize(ctx context.Context, authorizePayload *pb.
 	}
 
 	// Получаем айди базового счета покупателя
-	srcAccount, err := fetchAccount(tx, customerWallet.ID, "DEFAULT")
+	srcAccount, err := tx.fetchAccount(customerWallet.ID, "DEFAULT", p.fundingCurrency)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return nil, status.Error(codes.Internal, rollbackErr.Error())
@@ -91,7 +157,7 @@ func (this *Implementation) Authorize(ctx context.Context, authorizePayload *pb.
 	}
 
 	// Получаем айди расчетного счета покупателя
-	dstAccount, err := fetchAccount(tx, customerWallet.ID, "PAYMENT")
+	dstAccount, err := tx.fetchAccount(customerWallet.ID, "PAYMENT", p.fundingCurrency)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return nil, status.Error(codes.Internal, rollbackErr.Error())
@@ -100,7 +166,8 @@ func (this *Implementation) Authorize(ctx context.Context, authorizePayload *pb.
 	}
 
 	// Переводим деньги с базового на расчетный счет в количестве == платежу
-	err = transfer(tx, srcAccount, dstAccount, authorizePayload.Cents)
+	// (при конвертации - в сумме котировки в валюте счета покупателя)
+	err = transfer(tx, srcAccount, dstAccount, p.fundingAmount)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return nil, status.Error(codes.Internal, rollbackErr.Error())
@@ -108,16 +175,71 @@ func (this *Implementation) Authorize(ctx context.Context, authorizePayload *pb.
 		return nil, err
 	}
 
-	// Создаем айди транзакции для дальнейшей работы с ней
-	pid := uuid.NewString()
-	err = createTransaction(tx,
-		pid,
-		srcAccount,
-		dstAccount,
-		customerWallet,
-		customerWallet,
-		merchantWallet,
-		authorizePayload.Cents)
+	// Транзакция удержания по котировке сохраняет курс и спред конвертации
+	if p.fxQuoteID.Valid {
+		err = createFxTransaction(tx, pid, srcAccount, dstAccount, customerWallet, customerWallet, merchantWallet, p.fundingAmount, quote)
+	} else {
+		err = createTransaction(tx,
+			pid,
+			srcAccount,
+			dstAccount,
+			customerWallet,
+			customerWallet,
+			merchantWallet,
+			p.fundingAmount)
+	}
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Создаем платеж, по которому дальше отслеживается его состояние и срок действия авторизации
+	p.customerWalletID = customerWallet.ID
+	p.merchantWalletID = merchantWallet.ID
+	err = tx.createPayment(p, this.authorizationTTL)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+	err = tx.createPaymentSplits(pid, splits)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Сохраняем ответ для повторов запроса с тем же ключом идемпотентности
+	response.Pid = pid
+	err = saveIdempotencyResponse(tx, operationAuthorize, authorizePayload.GetIdempotencyKey(), response)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Событие об авторизации с долями всех продавцов (outbox)
+	p.status = statusAuthorized // Состояние, в котором создан платеж
+	shares := make([]producer.MerchantShare, len(splits))
+	for i, s := range splits {
+		shares[i] = producer.MerchantShare{
+			Merchant: producer.Party{UserID: merchantWallets[i].userID, WalletID: merchantWallets[i].ID},
+			Amount:   s.amount,
+		}
+	}
+	messages, err := producer.PaymentAuthorizedMessages(ctx, newPaymentEvent(p, customerWallet, shares))
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	err = tx.insertOutboxMessages(messages)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return nil, status.Error(codes.Internal, rollbackErr.Error())
@@ -131,16 +253,30 @@ func (this *Implementation) Authorize(ctx context.Context, authorizePayload *pb.
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	return &pb.AuthorizeResponse{Pid: pid}, nil
+	return response, nil
 }
 
 // Capture подтверждает ранее авторизованный платеж
 //
+// Платеж может подтверждаться частями: сумма каждого подтверждения задается
+// в capturePayload.Cents (0 - подтвердить весь остаток авторизации).
+// При финальном подтверждении (FinalCapture) неподтвержденный остаток
+// возвращается на базовый счет покупателя, иначе он остается удержанным.
+// С каждого подтверждения по тарифному плану продавца удерживается комиссия
+// платформы: она переводится со счета продавца на счет доходов платформы.
+// У платежа маркетплейса подтверждение распределяется по долям продавцов
+// (см. planCapture), каждый продавец получает средства на свой счет INCOMING.
+//
 // Основные шаги:
-//  1. Начало SQL транзакции
-//  2. Получение информации о предыдущей транзакции
-//  3. Перевод средств на счет продавца
-//  4. Создание новой транзакции
+//  1. Проверка суммы подтверждения
+//  2. Начало SQL транзакции и проверка ключа идемпотентности
+//  3. Получение платежа, расчет неподтвержденного остатка и проверка перехода состояния
+//  4. Перевод средств на счет продавца
+//  5. Создание новой транзакции
+//  6. Удержание комиссии платформы
+//  7. Возврат остатка покупателю при финальном подтверждении
+//  8. Обновление состояния платежа
+//  9. Запись события о подтверждении и записей для бухгалтерии в outbox
 //
 // Параметры:
 //   - ctx: контекст выполнения
@@ -150,14 +286,32 @@ func (this *Implementation) Authorize(ctx context.Context, authorizePayload *pb.
 //   - пустой ответ
 //   - ошибку в случае неудачи
 func (this *Implementation) Capture(ctx context.Context, capturePayload *pb.CapturePayload) (*emptypb.Empty, error) {
+	// Проверка суммы подтверждения
+	if capturePayload.GetCents() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "capture amount must not be negative")
+	}
+
 	// Начало транзакции (включаем изолированный запрос)
-	tx, err := this.db.Begin()
+	tx, err := this.store.Begin()
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	// Получение информации о транзакции по её pid
-	authorizeTransaction, err := fetchTransaction(tx, capturePayload.Pid)
+	// Повторный запрос с тем же ключом идемпотентности не подтверждает платеж еще раз
+	response := &emptypb.Empty{}
+	replayed, err := claimIdempotencyKey(tx, operationCapture, capturePayload.GetIdempotencyKey(), capturePayload, response)
+	if err != nil || replayed {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+
+	// Получение платежа по его pid с блокировкой строки до конца транзакции
+	payment, err := tx.lockPayment(capturePayload.Pid)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return nil, status.Error(codes.Internal, rollbackErr.Error())
@@ -165,8 +319,25 @@ func (this *Implementation) Capture(ctx context.Context, capturePayload *pb.Capt
 		return nil, err
 	}
 
-	// Получение информации о расчетном счете
-	srcAccount, err := fetchAccount(tx, authorizeTransaction.dstAccountWalletID, "PAYMENT")
+	// Повторное подтверждение закрытого платежа не должно переводить средства еще раз
+	if payment.status == statusCaptured || payment.status == statusRefunded {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, status.Error(codes.FailedPrecondition, "payment already captured")
+	}
+
+	// Истекшую авторизацию (в том числе неподтвержденный остаток частично подтвержденного платежа)
+	// подтвердить нельзя, даже если фоновый обработчик еще не снял удержание
+	if payment.expired {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, status.Error(codes.FailedPrecondition, "authorization expired")
+	}
+
+	// Получение долей продавцов платежа
+	splits, err := tx.fetchPaymentSplits(payment.pid)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return nil, status.Error(codes.Internal, rollbackErr.Error())
@@ -174,8 +345,39 @@ func (this *Implementation) Capture(ctx context.Context, capturePayload *pb.Capt
 		return nil, err
 	}
 
-	// Получение информации о счете продавца
-	dstMerchantAccount, err := fetchAccount(tx, authorizeTransaction.finalDstMerchantWalletID, "INCOMING")
+	// Неподтвержденный остаток авторизации
+	remainingAmount := payment.amount - payment.capturedAmount
+
+	// Распределение подтверждения по долям продавцов
+	// (без указанной суммы подтверждается весь остаток выбранных долей)
+	parts, err := planCapture(tx, splits, capturePayload.GetMerchantWalletUserId(), capturePayload.GetCents())
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+	var captureAmount int64
+	for _, part := range parts {
+		captureAmount += part.amount
+	}
+
+	// Подтверждение закрывает удержание, если оно финальное или подтвержден весь остаток
+	finalCapture := capturePayload.GetFinalCapture() || captureAmount == remainingAmount
+	event := eventCapturePartial
+	if finalCapture {
+		event = eventCaptureFull
+	}
+	nextStatus, err := nextPaymentStatus(payment.status, event)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Получение информации о расчетном счете (в валюте, с которой оплачен платеж)
+	srcAccount, err := tx.fetchAccount(payment.customerWalletID, "PAYMENT", payment.fundingCurrency)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return nil, status.Error(codes.Internal, rollbackErr.Error())
@@ -184,7 +386,320 @@ func (this *Implementation) Capture(ctx context.Context, capturePayload *pb.Capt
 	}
 
 	// Получение айди кошелька клиента
-	customerWallet, err := fetchWallet(tx, authorizeTransaction.srcUserID)
+	customerWallet, err := tx.fetchWalletWithWalletID(payment.customerWalletID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Котировка и счета обменного пункта для платежа с конвертацией
+	desk, quote, err := fetchPaymentFx(tx, payment, payment.fundingCurrency, payment.currency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Все счета, участвующие в переводах, блокируются заранее и в едином порядке,
+	// чтобы параллельные операции над теми же счетами не попадали в дедлок
+	accounts := []account{srcAccount}
+	if payment.fxQuoteID.Valid {
+		accounts = append(accounts, desk.srcAccount, desk.dstAccount)
+	}
+
+	// Получение кошельков и счетов продавцов и расчет комиссии платформы
+	// по тарифному плану каждого продавца (в валюте платежа)
+	var feeAmount int64
+	for i := range parts {
+		parts[i].merchantWallet, err = tx.fetchWalletWithWalletID(parts[i].split.merchantWalletID)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
+		parts[i].account, err = tx.fetchAccount(parts[i].merchantWallet.ID, "INCOMING", payment.currency)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
+		parts[i].plan, err = tx.fetchFeePlan(parts[i].merchantWallet.ID, payment.currency)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
+		parts[i].fee = parts[i].plan.fee(parts[i].amount)
+		feeAmount += parts[i].fee
+		accounts = append(accounts, parts[i].account)
+	}
+
+	var (
+		platformWallet wallet
+		revenueAccount account
+	)
+	if feeAmount > 0 {
+		// Получение кошелька платформы и счета доходов
+		platformWallet, revenueAccount, err = fetchRevenueAccount(tx, payment.currency)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
+		accounts = append(accounts, revenueAccount)
+	}
+
+	// Неподтвержденный остаток в валюте счета покупателя,
+	// который при финальном подтверждении возвращается покупателю
+	var releaseAmount int64
+	if finalCapture {
+		releaseAmount = payment.fundingAmount - fundingShare(payment, payment.capturedAmount+captureAmount)
+	}
+	var dstAccount account
+	if releaseAmount > 0 {
+		// Получение информации о базовом счете покупателя
+		dstAccount, err = tx.fetchAccount(customerWallet.ID, "DEFAULT", payment.fundingCurrency)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
+		accounts = append(accounts, dstAccount)
+	}
+	err = tx.lockAccounts(accounts...)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	capturedAmount := payment.capturedAmount
+	for _, part := range parts {
+		// Списываемая за долю часть удержания в валюте счета покупателя. Доля считается
+		// от накопленной подтвержденной суммы, поэтому округления не накапливаются
+		fundingCaptureAmount := fundingShare(payment, capturedAmount+part.amount) - fundingShare(payment, capturedAmount)
+		capturedAmount += part.amount
+
+		// Перевод средств с расчетного счета клиента на расчетный счет продавца
+		// и создание транзакции (при конвертации - через обменный пункт)
+		err = settle(
+			tx,                   // БД
+			payment,              // платеж
+			desk,                 // счета обменного пункта
+			quote,                // котировка платежа
+			srcAccount,           // счет отправления
+			part.account,         // счет получения
+			customerWallet,       // кошелек отправителя
+			part.merchantWallet,  // кошелек получателя
+			part.merchantWallet,  // конечный кошелек получателя
+			fundingCaptureAmount, // списываемая сумма
+			part.amount)          // зачисляемая сумма
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
+
+		// Удержание комиссии: перевод со счета продавца на счет доходов платформы
+		if part.fee > 0 {
+			err = transfer(tx, part.account, revenueAccount, part.fee)
+			if err != nil {
+				if rollbackErr := tx.Rollback(); rollbackErr != nil {
+					return nil, status.Error(codes.Internal, rollbackErr.Error())
+				}
+				return nil, err
+			}
+
+			// Создание транзакции комиссии
+			err = createTransaction(
+				tx,                  // БД
+				payment.pid,         // айди транзакции
+				part.account,        // счет отправления
+				revenueAccount,      // счет получения
+				part.merchantWallet, // кошелек отправителя
+				platformWallet,      // кошелек получателя
+				part.merchantWallet, // конечный кошелек получателя
+				part.fee)            // сумма
+			if err != nil {
+				if rollbackErr := tx.Rollback(); rollbackErr != nil {
+					return nil, status.Error(codes.Internal, rollbackErr.Error())
+				}
+				return nil, err
+			}
+		}
+
+		// Обновление подтвержденной части доли продавца
+		part.split.capturedAmount += part.amount
+		err = tx.updatePaymentSplit(*part.split)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
+	}
+
+	// При финальном подтверждении возвращаем неподтвержденный остаток на базовый счет покупателя
+	if releaseAmount > 0 {
+		// Возврат остатка с расчетного счета на базовый
+		err = transfer(tx, srcAccount, dstAccount, releaseAmount)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
+
+		// Создание транзакции возврата остатка
+		err = createTransaction(
+			tx,                      // БД
+			payment.pid,             // айди транзакции
+			srcAccount,              // счет отправления
+			dstAccount,              // счет получения
+			customerWallet,          // кошелек отправителя
+			customerWallet,          // кошелек получателя
+			parts[0].merchantWallet, // конечный кошелек получателя
+			releaseAmount)           // сумма
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
+	}
+
+	// Обновление подтвержденной суммы и состояния платежа
+	payment.capturedAmount += captureAmount
+	payment.status = nextStatus
+	err = tx.updatePayment(payment)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Сохраняем ответ для повторов запроса с тем же ключом идемпотентности
+	err = saveIdempotencyResponse(tx, operationCapture, capturePayload.GetIdempotencyKey(), response)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Событие о подтверждении с разбивкой суммы каждого продавца на комиссию и выручку.
+	// События записываются в outbox в той же транзакции, в Кафку их отправляет RunOutboxRelay
+	shares := make([]producer.MerchantShare, len(parts))
+	for i, part := range parts {
+		shares[i] = producer.MerchantShare{
+			Merchant: producer.Party{UserID: part.merchantWallet.userID, WalletID: part.merchantWallet.ID},
+			Amount:   part.amount,
+			Fee: &producer.FeeBreakdown{
+				MerchantUserID: part.merchantWallet.userID,
+				PercentBps:     part.plan.percentBps,
+				FixedCents:     part.plan.fixedCents,
+				FeeCents:       part.fee,
+				NetCents:       part.amount - part.fee,
+			},
+		}
+	}
+	messages, err := producer.PaymentCapturedMessages(ctx, newPaymentEvent(payment, customerWallet, shares))
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	err = tx.insertOutboxMessages(messages)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Запись транзакции в БД
+	err = tx.Commit()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return response, nil
+}
+
+// Void отменяет ранее авторизованный платеж и возвращает удержанные средства
+//
+// Основные шаги:
+//  1. Начало SQL транзакции
+//  2. Получение платежа и проверка перехода состояния
+//  3. Перевод средств с расчетного счета обратно на базовый счет покупателя
+//  4. Создание транзакции возврата
+//  5. Перевод платежа в состояние VOIDED
+//  6. Запись события об отмене в outbox
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - voidPayload: данные для отмены платежа
+//
+// Возвращает:
+//   - пустой ответ
+//   - ошибку в случае неудачи
+func (this *Implementation) Void(ctx context.Context, voidPayload *pb.VoidPayload) (*emptypb.Empty, error) {
+	// Начало транзакции (включаем изолированный запрос)
+	tx, err := this.store.Begin()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Получение платежа по его pid с блокировкой строки до конца транзакции
+	payment, err := tx.lockPayment(voidPayload.Pid)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Отменить можно только платеж, по которому еще не было подтверждений
+	nextStatus, err := nextPaymentStatus(payment.status, eventVoid)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Получение информации о расчетном счете покупателя (на нем удерживаются средства)
+	srcAccount, err := tx.fetchAccount(payment.customerWalletID, "PAYMENT", payment.fundingCurrency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Получение информации о базовом счете покупателя
+	dstAccount, err := tx.fetchAccount(payment.customerWalletID, "DEFAULT", payment.fundingCurrency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Получение айди кошелька клиента
+	customerWallet, err := tx.fetchWalletWithWalletID(payment.customerWalletID)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return nil, status.Error(codes.Internal, rollbackErr.Error())
@@ -193,7 +708,7 @@ func (this *Implementation) Capture(ctx context.Context, capturePayload *pb.Capt
 	}
 
 	// Получение айди кошелька продавца
-	merchantWallet, err := fetchWalletWithWalletID(tx, authorizeTransaction.finalDstMerchantWalletID)
+	merchantWallet, err := tx.fetchWalletWithWalletID(payment.merchantWalletID)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return nil, status.Error(codes.Internal, rollbackErr.Error())
@@ -201,8 +716,9 @@ func (this *Implementation) Capture(ctx context.Context, capturePayload *pb.Capt
 		return nil, err
 	}
 
-	// Перевод средств с расчетного счета клиента на расчетный счет продавца
-	err = transfer(tx, srcAccount, dstMerchantAccount, authorizeTransaction.amount)
+	// Возврат удержанных средств с расчетного счета на базовый (без конвертации,
+	// в валюте, с которой оплачен платеж)
+	err = transfer(tx, srcAccount, dstAccount, payment.fundingAmount)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return nil, status.Error(codes.Internal, rollbackErr.Error())
@@ -210,20 +726,61 @@ func (this *Implementation) Capture(ctx context.Context, capturePayload *pb.Capt
 		return nil, err
 	}
 
-	// Создание транзакции перевода средств от клиента продавцу
+	// Создание транзакции возврата средств (reversal)
 	err = createTransaction(
-		tx,                          // БД
-		authorizeTransaction.pid,    // айди транзакции
-		srcAccount,                  // счет отправления
-		dstMerchantAccount,          // счет получения
-		customerWallet,              // кошелек отправителя
-		merchantWallet,              // кошелек получателя
-		merchantWallet,              // конечный кошелек получателя
-		authorizeTransaction.amount) // сумма
+		tx,                    // БД
+		payment.pid,           // айди транзакции
+		srcAccount,            // счет отправления
+		dstAccount,            // счет получения
+		customerWallet,        // кошелек отправителя
+		customerWallet,        // кошелек получателя
+		merchantWallet,        // конечный кошелек получателя
+		payment.fundingAmount) // сумма
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Перевод платежа в состояние VOIDED
+	payment.status = nextStatus
+	err = tx.updatePayment(payment)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return nil, status.Error(codes.Internal, rollbackErr.Error())
 		}
+		return nil, err
+	}
+
+	// Событие об отмене: доли всех продавцов освобождаются целиком (outbox)
+	splits, err := tx.fetchPaymentSplits(payment.pid)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+	shares, err := splitShares(tx, splits, func(s paymentSplit) int64 { return s.amount })
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+	messages, err := producer.PaymentVoidedMessages(ctx, newPaymentEvent(payment, customerWallet, shares))
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	err = tx.insertOutboxMessages(messages)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
 	}
 
 	// Запись транзакции в БД
@@ -232,144 +789,330 @@ func (this *Implementation) Capture(ctx context.Context, capturePayload *pb.Capt
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	producer.SendCaptureMessage(authorizeTransaction.pid, authorizeTransaction.srcUserID, authorizeTransaction.amount)
-
 	return &emptypb.Empty{}, nil
-
 }
 
-func fetchWallet(tx *sql.Tx, userID string) (wallet, error) {
-	var w wallet
-	stmt, err := tx.Prepare("SELECT id, user_id, wallet_type FROM wallet WHERE user_id=?")
+// Refund возвращает покупателю средства по подтвержденному платежу
+//
+// Допускается несколько частичных возвратов, пока их сумма не превышает
+// подтвержденную сумму доли продавца. У платежа с несколькими продавцами
+// возврат делается с доли продавца refundPayload.MerchantWalletUserId.
+//
+// Основные шаги:
+//  1. Проверка суммы возврата
+//  2. Начало SQL транзакции
+//  3. Получение платежа и доли продавца, расчет доступной к возврату суммы и проверка перехода состояния
+//  4. Возврат продавцу соответствующей части удержанной комиссии со счета доходов платформы
+//  5. Перевод средств со счета продавца на базовый счет покупателя
+//  6. Создание транзакций возврата комиссии и возврата платежа
+//  7. Обновление возвращенной суммы и состояния платежа
+//  8. Запись события о возврате и записи для бухгалтерии в outbox
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - refundPayload: данные для возврата платежа
+//
+// Возвращает:
+//   - пустой ответ
+//   - ошибку в случае неудачи
+func (this *Implementation) Refund(ctx context.Context, refundPayload *pb.RefundPayload) (*emptypb.Empty, error) {
+	// Проверка суммы возврата
+	if refundPayload.GetCents() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "refund amount must be positive")
+	}
+
+	// Начало транзакции (включаем изолированный запрос)
+	tx, err := this.store.Begin()
 	if err != nil {
-		return w, status.Error(codes.Internal, err.Error())
+		return nil, status.Error(codes.Internal, err.Error())
 	}
-	defer stmt.Close()
 
-	err = stmt.QueryRow(userID).Scan(&w.ID, &w.userID, &w.walletType)
+	// Получение платежа по его pid с блокировкой строки до конца транзакции
+	payment, err := tx.lockPayment(refundPayload.Pid)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return w, status.Error(codes.NotFound, err.Error())
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
 		}
-		return w, status.Error(codes.Internal, err.Error())
+		return nil, err
 	}
-	return w, nil
-}
 
-func fetchWalletWithWalletID(tx *sql.Tx, walletID int32) (wallet, error) {
-	var w wallet
-	stmt, err := tx.Prepare("SELECT id, user_id, wallet_type FROM wallet WHERE id=?")
+	// Получение долей продавцов и выбор доли, с которой делается возврат
+	splits, err := tx.fetchPaymentSplits(payment.pid)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+	i, err := selectSplit(tx, splits, refundPayload.GetMerchantWalletUserId())
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+	split := &splits[i]
+
+	// Вернуть можно только подтвержденные и еще не возвращенные средства доли продавца
+	if refundPayload.Cents > split.capturedAmount-split.refundedAmount {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, status.Error(codes.FailedPrecondition, "refund amount exceeds captured amount")
+	}
+
+	// Платеж полностью возвращен, когда возвращены все подтвержденные средства всех долей
+	event := eventRefundPartial
+	if refundPayload.Cents == payment.capturedAmount-payment.refundedAmount {
+		event = eventRefundFull
+	}
+	nextStatus, err := nextPaymentStatus(payment.status, event)
 	if err != nil {
-		return w, status.Error(codes.Internal, err.Error())
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
 	}
-	defer stmt.Close()
 
-	err = stmt.QueryRow(walletID).Scan(&w.ID, &w.userID, &w.walletType)
+	// Получение информации о счете продавца
+	srcMerchantAccount, err := tx.fetchAccount(split.merchantWalletID, "INCOMING", payment.currency)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return w, status.Error(codes.NotFound, err.Error())
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
 		}
-		return w, status.Error(codes.Internal, err.Error())
+		return nil, err
 	}
-	return w, nil
-}
 
-func fetchAccount(tx *sql.Tx, walletID int32, accountType string) (account, error) {
-	var a account
-	stmt, err := tx.Prepare("SELECT id, cents, account_type, wallet_id FROM account WHERE wallet_id=? AND account_type=?")
+	// Получение информации о базовом счете покупателя (в валюте, с которой оплачен платеж)
+	dstAccount, err := tx.fetchAccount(payment.customerWalletID, "DEFAULT", payment.fundingCurrency)
 	if err != nil {
-		return a, status.Error(codes.Internal, err.Error())
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
 	}
-	defer stmt.Close()
 
-	err = stmt.QueryRow(walletID, accountType).Scan(&a.ID, &a.cents, &a.accountType, &a.walletID)
+	// Получение айди кошелька клиента
+	customerWallet, err := tx.fetchWalletWithWalletID(payment.customerWalletID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return a, status.Error(codes.NotFound, err.Error())
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
 		}
-		return a, status.Error(codes.Internal, err.Error())
+		return nil, err
 	}
-	return a, nil
-}
 
-func transfer(tx *sql.Tx, srcAccount account, dstAccount account, amount int64) error {
-	if srcAccount.cents < amount {
-		return status.Error(codes.Aborted, "not enough money")
+	// Получение айди кошелька продавца
+	merchantWallet, err := tx.fetchWalletWithWalletID(split.merchantWalletID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
 	}
 
-	// SQL запрос для обновления счетов
-	stmt, err := tx.Prepare("UPDATE account SET cents=? WHERE id=?")
+	// Котировка и счета обменного пункта для платежа с конвертацией
+	desk, quote, err := fetchPaymentFx(tx, payment, payment.currency, payment.fundingCurrency)
 	if err != nil {
-		return status.Error(codes.Internal, err.Error())
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
 	}
-	defer stmt.Close()
 
-	// Снимаем деньги с базового аккаунта (srcAccount)
-	_, err = stmt.Exec(srcAccount.cents-amount, srcAccount.ID)
+	// Комиссия платформы при подтверждении уже списана со счета продавца,
+	// поэтому платформа возвращает продавцу соответствующую часть комиссии
+	transactions, err := tx.fetchTransactions(payment.pid)
 	if err != nil {
-		return status.Error(codes.Internal, err.Error())
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+	feeAmount := refundFee(transactions, *split, refundPayload.Cents)
+	if feeAmount > 0 {
+		// Получение кошелька платформы и счета доходов
+		platformWallet, revenueAccount, err := fetchRevenueAccount(tx, payment.currency)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
+
+		// Перевод комиссии со счета доходов платформы на счет продавца
+		err = transfer(tx, revenueAccount, srcMerchantAccount, feeAmount)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
+
+		// Создание транзакции возврата комиссии
+		err = createTransaction(
+			tx,                 // БД
+			payment.pid,        // айди транзакции
+			revenueAccount,     // счет отправления
+			srcMerchantAccount, // счет получения
+			platformWallet,     // кошелек отправителя
+			merchantWallet,     // кошелек получателя
+			merchantWallet,     // конечный кошелек продавца
+			feeAmount)          // сумма
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return nil, err
+		}
 	}
 
-	// Перекидываем деньги на расчетный счет (dstAccount)
-	_, err = stmt.Exec(dstAccount.cents+amount, dstAccount.ID)
+	// Покупателю возвращается соответствующая часть удержания по курсу платежа
+	refundFundingAmount := fundingShare(payment, payment.refundedAmount+refundPayload.Cents) - fundingShare(payment, payment.refundedAmount)
+
+	// Перевод средств со счета продавца на базовый счет покупателя
+	// и создание транзакции возврата (при конвертации - через обменный пункт)
+	err = settle(
+		tx,                  // БД
+		payment,             // платеж
+		desk,                // счета обменного пункта
+		quote,               // котировка платежа
+		srcMerchantAccount,  // счет отправления
+		dstAccount,          // счет получения
+		merchantWallet,      // кошелек отправителя
+		customerWallet,      // кошелек получателя
+		merchantWallet,      // конечный кошелек продавца
+		refundPayload.Cents, // списываемая сумма
+		refundFundingAmount) // зачисляемая сумма
 	if err != nil {
-		return status.Error(codes.Internal, err.Error())
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Обновление возвращенной части доли продавца
+	split.refundedAmount += refundPayload.Cents
+	err = tx.updatePaymentSplit(*split)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
 	}
-	return nil
-}
 
-func createTransaction(tx *sql.Tx, pid string, srcAccount account, dstAccount account, srcWallet wallet, dstWallet wallet, finalDstWallet wallet, amount int64) error {
+	// Обновление возвращенной суммы и состояния платежа
+	payment.refundedAmount += refundPayload.Cents
+	payment.status = nextStatus
+	err = tx.updatePayment(payment)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
 
-	// SQL запрос
-	stmt, err := tx.Prepare(insertTransactionQuery)
+	// Событие о возврате с доли продавца (outbox). Разбивка показывает, сколько из возврата
+	// покрыто возвращенной комиссией платформы, а сколько - выручкой продавца
+	share := producer.MerchantShare{
+		Merchant: producer.Party{UserID: merchantWallet.userID, WalletID: merchantWallet.ID},
+		Amount:   refundPayload.Cents,
+	}
+	if feeAmount > 0 {
+		share.Fee = &producer.FeeBreakdown{
+			MerchantUserID: merchantWallet.userID,
+			FeeCents:       feeAmount,
+			NetCents:       refundPayload.Cents - feeAmount,
+		}
+	}
+	messages, err := producer.PaymentRefundedMessages(ctx, newPaymentEvent(payment, customerWallet, []producer.MerchantShare{share}))
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	err = tx.insertOutboxMessages(messages)
 	if err != nil {
-		return status.Error(codes.Internal, err.Error())
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(
-		pid,                    // Уникальный айди транзакции
-		srcWallet.userID,       // Айди кошелька отправителя
-		dstWallet.userID,       // Айди кошелька получателя
-		srcWallet.ID,           // Айди счета отправления
-		dstWallet.ID,           // Айди счета получения
-		srcAccount.accountType, // Тип счета отправления
-		dstAccount.accountType, // Тип счета получения
-		finalDstWallet.ID,      // Айди кошелька продавца
-		amount)
+	// Запись транзакции в БД
+	err = tx.Commit()
 	if err != nil {
-		return status.Error(codes.Internal, err.Error())
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	return nil
+	return &emptypb.Empty{}, nil
 }
 
-func fetchTransaction(tx *sql.Tx, pid string) (transaction, error) {
-	var t transaction
+// transfer переводит amount центов со счета srcAccount на счет dstAccount
+//
+// Балансы изменяются относительно текущих значений в хранилище по заблокированным счетам,
+// поэтому значения cents в переданных структурах не используются и могут быть устаревшими.
+// Списание выполняется только при достаточном остатке (cents >= amount),
+// иначе возвращается ошибка Aborted (кроме клиринговых счетов). Счета в разных валютах не смешиваются.
+func transfer(tx UnitOfWork, srcAccount account, dstAccount account, amount int64) error {
+	if amount <= 0 {
+		return status.Error(codes.InvalidArgument, "transfer amount must be positive")
+	}
+
+	// Переводы возможны только между счетами в одной валюте
+	if srcAccount.currency != dstAccount.currency {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("currency mismatch: %s -> %s", srcAccount.currency, dstAccount.currency))
+	}
 
-	stmt, err := tx.Prepare(selectTransactionQuery)
+	// Блокируем оба счета в порядке возрастания айди
+	err := tx.lockAccounts(srcAccount, dstAccount)
 	if err != nil {
-		return t, status.Error(codes.Internal, err.Error())
+		return err
 	}
-	defer stmt.Close()
 
-	err = stmt.QueryRow(pid).Scan(
-		&t.ID,
-		&t.pid,
-		&t.srcUserID,
-		&t.dstUserID,
-		&t.srcAccountWalletID,
-		&t.dstAccountWalletID,
-		&t.srcAccountType,
-		&t.dstAccountType,
-		&t.finalDstMerchantWalletID,
-		&t.amount)
+	// Снимаем деньги со счета отправления (srcAccount), только если их хватает.
+	// Клиринговые счета отражают деньги, пришедшие извне, и могут уходить в минус
+	err = tx.debitAccount(srcAccount.ID, amount, srcAccount.accountType == clearingAccountType)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return t, status.Error(codes.NotFound, err.Error())
-		}
-		return t, status.Error(codes.Internal, err.Error())
+		return err
 	}
 
-	return t, nil
+	// Перекидываем деньги на счет получения (dstAccount)
+	return tx.creditAccount(dstAccount.ID, amount)
+}
+
+func createTransaction(tx UnitOfWork, pid string, srcAccount account, dstAccount account, srcWallet wallet, dstWallet wallet, finalDstWallet wallet, amount int64) error {
+	return tx.insertTransaction(pid, srcAccount, dstAccount, srcWallet, dstWallet, finalDstWallet, amount, nil, sql.NullString{})
+}
+
+// createFxTransaction создает транзакцию, проведенную по котировке quote:
+// вместе с суммой сохраняются курс конвертации, рыночный курс и спред
+func createFxTransaction(tx UnitOfWork, pid string, srcAccount account, dstAccount account, srcWallet wallet, dstWallet wallet, finalDstWallet wallet, amount int64, quote fxQuote) error {
+	return tx.insertTransaction(pid, srcAccount, dstAccount, srcWallet, dstWallet, finalDstWallet, amount, &quote, sql.NullString{})
+}
+
+// createTransferTransaction создает транзакцию перевода между пользователями
+// с комментарием отправителя memo
+func createTransferTransaction(tx UnitOfWork, pid string, srcAccount account, dstAccount account, srcWallet wallet, dstWallet wallet, amount int64, memo string) error {
+	return tx.insertTransaction(pid, srcAccount, dstAccount, srcWallet, dstWallet, dstWallet, amount, nil, sql.NullString{String: memo, Valid: memo != ""})
+}
+
+// isCaptureTransaction сообщает, является ли транзакция подтверждением платежа (PAYMENT -> INCOMING)
+// или одной из ее половин при конвертации (PAYMENT -> FX, FX -> INCOMING)
+func isCaptureTransaction(t transaction) bool {
+	return (t.srcAccountType == "PAYMENT" && (t.dstAccountType == "INCOMING" || t.dstAccountType == fxAccountType)) ||
+		(t.srcAccountType == fxAccountType && t.dstAccountType == "INCOMING")
+}
+
+// isReleaseTransaction сообщает, является ли транзакция снятием удержания (PAYMENT -> DEFAULT):
+// отменой авторизации или возвратом остатка при финальном подтверждении
+func isReleaseTransaction(t transaction) bool {
+	return t.srcAccountType == "PAYMENT" && t.dstAccountType == "DEFAULT"
+}
+
+// isRefundTransaction сообщает, является ли транзакция возвратом средств покупателю (INCOMING -> DEFAULT)
+// или одной из ее половин при конвертации (INCOMING -> FX, FX -> DEFAULT)
+func isRefundTransaction(t transaction) bool {
+	return (t.srcAccountType == "INCOMING" && (t.dstAccountType == "DEFAULT" || t.dstAccountType == fxAccountType)) ||
+		(t.srcAccountType == fxAccountType && t.dstAccountType == "DEFAULT")
 }
@@ -0,0 +1,252 @@
+package mm
+
+import (
+	"context"
+	"database/sql"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+	"github.com/sunr3d/gomicro/internal/payout"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Тесты работают с настоящей MySQL, инициализированной скриптом init.sql.
+// Строка подключения задается переменной окружения MONEY_MOVEMENT_TEST_DSN, например:
+//
+//	money_movement_user:MM123@tcp(localhost:3306)/money_movement?parseTime=true
+//
+// Без нее тесты пропускаются.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("MONEY_MOVEMENT_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MONEY_MOVEMENT_TEST_DSN is not set")
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Log(err)
+		}
+	})
+
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// createTestWallet создает кошелек с уникальным user_id и счетами с заданными балансами
+func createTestWallet(t *testing.T, db *sql.DB, walletType string, balances map[string]int64) string {
+	t.Helper()
+
+	userID := uuid.NewString()
+	res, err := db.Exec("INSERT INTO wallet (user_id, wallet_type) VALUES (?, ?)", userID, walletType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletID, err := res.LastInsertId()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for accountType, cents := range balances {
+		_, err = db.Exec("INSERT INTO account (cents, account_type, wallet_id) VALUES (?, ?, ?)", cents, accountType, walletID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return userID
+}
+
+// accountBalance возвращает баланс счета accountType кошелька пользователя userID
+func accountBalance(t *testing.T, db *sql.DB, userID string, accountType string) int64 {
+	t.Helper()
+
+	var cents int64
+	err := db.QueryRow(
+		"SELECT a.cents FROM account a JOIN wallet w ON w.id = a.wallet_id WHERE w.user_id = ? AND a.account_type = ?",
+		userID, accountType).Scan(&cents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cents
+}
+
+func authorizeTestPayment(t *testing.T, impl *Implementation, customerID string, merchantID string, cents int64) string {
+	t.Helper()
+
+	resp, err := impl.Authorize(context.Background(), &pb.AuthorizePayload{
+		CustomerWalletUserID: customerID,
+		MerchantWalletUserID: merchantID,
+		Cents:                cents,
+		Currency:             "USD",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Pid
+}
+
+func TestCaptureTwice(t *testing.T) {
+	db := openTestDB(t)
+	impl := NewMoneyMovementImplementation(NewMySQLStore(db), time.Hour, time.Minute, 0, payout.NewFakeBank(0, 0), nil)
+
+	customerID := createTestWallet(t, db, "CUSTOMER", map[string]int64{"DEFAULT": 10000, "PAYMENT": 0})
+	merchantID := createTestWallet(t, db, "MERCHANT", map[string]int64{"INCOMING": 0})
+	pid := authorizeTestPayment(t, impl, customerID, merchantID, 1000)
+
+	if _, err := impl.Capture(context.Background(), &pb.CapturePayload{Pid: pid}); err != nil {
+		t.Fatalf("first capture: %v", err)
+	}
+
+	_, err := impl.Capture(context.Background(), &pb.CapturePayload{Pid: pid})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("second capture: expected FailedPrecondition, got %v", err)
+	}
+
+	if got := accountBalance(t, db, merchantID, "INCOMING"); got != 1000 {
+		t.Errorf("merchant INCOMING = %d, want 1000", got)
+	}
+}
+
+func TestCaptureConcurrent(t *testing.T) {
+	db := openTestDB(t)
+	impl := NewMoneyMovementImplementation(NewMySQLStore(db), time.Hour, time.Minute, 0, payout.NewFakeBank(0, 0), nil)
+
+	customerID := createTestWallet(t, db, "CUSTOMER", map[string]int64{"DEFAULT": 10000, "PAYMENT": 0})
+	merchantID := createTestWallet(t, db, "MERCHANT", map[string]int64{"INCOMING": 0})
+	pid := authorizeTestPayment(t, impl, customerID, merchantID, 1000)
+
+	const workers = 10
+	var (
+		wg        sync.WaitGroup
+		start     = make(chan struct{})
+		errs      = make(chan error, workers)
+		succeeded int
+	)
+
+	// Все горутины стартуют одновременно, чтобы подтверждения пересекались во времени
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := impl.Capture(context.Background(), &pb.CapturePayload{Pid: pid})
+			errs <- err
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		switch status.Code(err) {
+		case codes.OK:
+			succeeded++
+		case codes.FailedPrecondition:
+		default:
+			t.Errorf("unexpected capture error: %v", err)
+		}
+	}
+
+	if succeeded != 1 {
+		t.Fatalf("%d captures succeeded, want exactly 1", succeeded)
+	}
+	if got := accountBalance(t, db, merchantID, "INCOMING"); got != 1000 {
+		t.Errorf("merchant INCOMING = %d, want 1000", got)
+	}
+	if got := accountBalance(t, db, customerID, "PAYMENT"); got != 0 {
+		t.Errorf("customer PAYMENT = %d, want 0", got)
+	}
+}
+
+// TestTransferStress параллельно авторизует платежи одного кошелька, затем подтверждает
+// или отменяет их и проверяет, что балансы не уходят в минус и сумма средств не меняется
+func TestTransferStress(t *testing.T) {
+	db := openTestDB(t)
+	impl := NewMoneyMovementImplementation(NewMySQLStore(db), time.Hour, time.Minute, 0, payout.NewFakeBank(0, 0), nil)
+
+	const (
+		initialBalance = 10000
+		amount         = 100
+		workers        = 20
+		iterations     = 10
+	)
+
+	customerID := createTestWallet(t, db, "CUSTOMER", map[string]int64{"DEFAULT": initialBalance, "PAYMENT": 0})
+	merchantID := createTestWallet(t, db, "MERCHANT", map[string]int64{"INCOMING": 0})
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		captured int64
+		start    = make(chan struct{})
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			<-start
+			for i := 0; i < iterations; i++ {
+				resp, err := impl.Authorize(context.Background(), &pb.AuthorizePayload{
+					CustomerWalletUserID: customerID,
+					MerchantWalletUserID: merchantID,
+					Cents:                amount,
+					Currency:             "USD",
+				})
+				if err != nil {
+					// Нехватка средств - ожидаемый исход при конкуренции за баланс
+					if status.Code(err) != codes.Aborted {
+						t.Errorf("authorize: %v", err)
+					}
+					continue
+				}
+
+				// Половина платежей подтверждается, половина отменяется
+				if (w+i)%2 == 0 {
+					_, err = impl.Capture(context.Background(), &pb.CapturePayload{Pid: resp.Pid})
+					if err == nil {
+						mu.Lock()
+						captured += amount
+						mu.Unlock()
+					}
+				} else {
+					_, err = impl.Void(context.Background(), &pb.VoidPayload{Pid: resp.Pid})
+				}
+				if err != nil {
+					t.Errorf("settle %s: %v", resp.Pid, err)
+				}
+			}
+		}(w)
+	}
+	close(start)
+	wg.Wait()
+
+	defaultBalance := accountBalance(t, db, customerID, "DEFAULT")
+	paymentBalance := accountBalance(t, db, customerID, "PAYMENT")
+	incomingBalance := accountBalance(t, db, merchantID, "INCOMING")
+
+	if defaultBalance < 0 || paymentBalance < 0 || incomingBalance < 0 {
+		t.Fatalf("negative balance: DEFAULT=%d PAYMENT=%d INCOMING=%d", defaultBalance, paymentBalance, incomingBalance)
+	}
+	if paymentBalance != 0 {
+		t.Errorf("customer PAYMENT = %d, want 0 after all payments settled", paymentBalance)
+	}
+	if incomingBalance != captured {
+		t.Errorf("merchant INCOMING = %d, want %d captured", incomingBalance, captured)
+	}
+	if total := defaultBalance + paymentBalance + incomingBalance; total != initialBalance {
+		t.Errorf("total balance drifted: %d, want %d", total, initialBalance)
+	}
+}
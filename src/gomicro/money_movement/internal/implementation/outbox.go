@@ -0,0 +1,140 @@
+package mm
+
+import (
+	"context"
+	"github.com/sunr3d/gomicro/internal/producer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+	"time"
+)
+
+const (
+	outboxBatchSize  = 100             // События отправляются пачками в порядке записи
+	outboxMaxBackoff = 5 * time.Minute // Предельная пауза между проходами при недоступной Кафке
+)
+
+// RunOutboxRelay периодически отправляет в Кафку события, накопленные в outbox.
+// Блокирует вызывающую горутину до отмены ctx.
+//
+// Пока Кафка недоступна, пауза между проходами удваивается (до outboxMaxBackoff)
+// и возвращается к interval после первой успешной отправки.
+//
+// Параметры:
+//   - ctx: контекст, отмена которого останавливает отправку
+//   - interval: интервал между проходами
+func (this *Implementation) RunOutboxRelay(ctx context.Context, interval time.Duration) {
+	delay := interval
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			sent, err := this.RelayOutbox(ctx)
+			if sent > 0 {
+				log.Printf("outbox relay: %d messages sent\n", sent)
+			}
+			if err != nil {
+				delay = min(delay*2, outboxMaxBackoff)
+				log.Printf("outbox relay: %v (retry in %s)\n", err, delay)
+			} else {
+				delay = interval
+			}
+			timer.Reset(delay)
+		}
+	}
+}
+
+// RelayOutbox отправляет в Кафку все неотправленные события из outbox
+//
+// События отправляются строго в порядке записи: на первой ошибке проход
+// останавливается, а событие остается в outbox со счетчиком попыток и текстом
+// ошибки до следующего прохода. Доставка гарантируется не менее одного раза:
+// если событие ушло в Кафку, но отметка об отправке не сохранилась,
+// оно будет отправлено повторно.
+//
+// Возвращает:
+//   - количество отправленных событий
+//   - ошибку отправки или хранилища
+func (this *Implementation) RelayOutbox(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		messages, err := this.fetchPendingOutboxMessages()
+		if err != nil {
+			return total, err
+		}
+		if len(messages) == 0 {
+			return total, nil
+		}
+
+		batch := make([]producer.Message, len(messages))
+		for i, m := range messages {
+			batch[i] = m.message
+		}
+		sent, publishErr := this.publisher.Publish(batch)
+
+		err = this.markOutboxMessages(messages, sent, publishErr)
+		if err != nil {
+			return total, err
+		}
+		total += sent
+		if publishErr != nil {
+			return total, publishErr
+		}
+
+		if len(messages) < outboxBatchSize || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
+
+// fetchPendingOutboxMessages возвращает очередную пачку неотправленных событий
+func (this *Implementation) fetchPendingOutboxMessages() ([]outboxMessage, error) {
+	tx, err := this.store.Begin()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer func() {
+		// Выборка только читает outbox, поэтому всегда откатывается
+		_ = tx.Rollback()
+	}()
+
+	return tx.fetchPendingOutboxMessages(outboxBatchSize)
+}
+
+// markOutboxMessages сохраняет результат отправки пачки: первые sent событий
+// отмечаются отправленными, а следующее за ними (если publishErr != nil) - неудачной попыткой
+func (this *Implementation) markOutboxMessages(messages []outboxMessage, sent int, publishErr error) error {
+	tx, err := this.store.Begin()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	for _, m := range messages[:sent] {
+		err = tx.markOutboxMessageSent(m.ID)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return err
+		}
+	}
+	if publishErr != nil && sent < len(messages) {
+		err = tx.markOutboxMessageFailed(messages[sent].ID, publishErr.Error())
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return status.Error(codes.Internal, rollbackErr.Error())
+			}
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package mm
+
+import (
+	"context"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetPayment возвращает текущее состояние платежа, его суммы и историю движения средств
+//
+// Основные шаги:
+//  1. Начало SQL транзакции
+//  2. Получение платежа и кошельков покупателя и продавца
+//  3. Получение долей продавцов платежа
+//  4. Получение истории транзакций платежа
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - getPaymentPayload: идентификатор платежа
+//
+// Возвращает:
+//   - платеж с историей транзакций
+//   - ошибку в случае неудачи
+func (this *Implementation) GetPayment(ctx context.Context, getPaymentPayload *pb.GetPaymentPayload) (*pb.Payment, error) {
+	// Начало транзакции (все чтения выполняются по одному снимку данных)
+	tx, err := this.store.Begin()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer func() {
+		// Транзакция только читает данные, поэтому всегда откатывается
+		_ = tx.Rollback()
+	}()
+
+	// Получение платежа по его pid
+	payment, err := tx.fetchPayment(getPaymentPayload.Pid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Получение кошельков покупателя и продавца
+	customerWallet, err := tx.fetchWalletWithWalletID(payment.customerWalletID)
+	if err != nil {
+		return nil, err
+	}
+	merchantWallet, err := tx.fetchWalletWithWalletID(payment.merchantWalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Получение долей продавцов платежа
+	splits, err := tx.fetchPaymentSplits(payment.pid)
+	if err != nil {
+		return nil, err
+	}
+	paymentSplits := make([]*pb.PaymentSplit, 0, len(splits))
+	for _, s := range splits {
+		splitWallet, err := tx.fetchWalletWithWalletID(s.merchantWalletID)
+		if err != nil {
+			return nil, err
+		}
+		paymentSplits = append(paymentSplits, &pb.PaymentSplit{
+			MerchantWalletUserId: splitWallet.userID,
+			AuthorizedCents:      s.amount,
+			CapturedCents:        s.capturedAmount,
+			RefundedCents:        s.refundedAmount,
+		})
+	}
+
+	// Получение истории транзакций платежа
+	transactions, err := tx.fetchTransactions(payment.pid)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*pb.PaymentTransaction, 0, len(transactions))
+	for _, t := range transactions {
+		history = append(history, &pb.PaymentTransaction{
+			Type:           transactionType(t),
+			SrcUserID:      t.srcUserID,
+			DstUserID:      t.dstUserID,
+			SrcAccountType: t.srcAccountType,
+			DstAccountType: t.dstAccountType,
+			Cents:          t.amount,
+			Currency:       t.currency,
+			FxQuoteId:      t.fxQuoteID.String,
+			FxRate:         t.fxRate.String,
+			CreatedAt:      timestamppb.New(t.createdAt),
+		})
+	}
+
+	return &pb.Payment{
+		Pid:                  payment.pid,
+		Status:               string(payment.status),
+		CustomerWalletUserID: customerWallet.userID,
+		MerchantWalletUserID: merchantWallet.userID,
+		Currency:             payment.currency,
+		AuthorizedCents:      payment.amount,
+		CapturedCents:        payment.capturedAmount,
+		RefundedCents:        payment.refundedAmount,
+		History:              history,
+		CreatedAt:            timestamppb.New(payment.createdAt),
+		UpdatedAt:            timestamppb.New(payment.updatedAt),
+		ExpiresAt:            timestamppb.New(payment.expiresAt),
+		FundingCurrency:      payment.fundingCurrency,
+		FundingCents:         payment.fundingAmount,
+		FxQuoteId:            payment.fxQuoteID.String,
+		Splits:               paymentSplits,
+	}, nil
+}
+
+// transactionType определяет вид транзакции платежа по типам счетов отправления и получения.
+// Конвертация через обменный пункт состоит из двух транзакций одного вида
+func transactionType(t transaction) string {
+	switch {
+	case isCaptureTransaction(t):
+		return "CAPTURE"
+	case isReleaseTransaction(t):
+		return "RELEASE"
+	case isRefundTransaction(t):
+		return "REFUND"
+	case isFeeTransaction(t):
+		return "FEE"
+	case isFeeRefundTransaction(t):
+		return "FEE_REFUND"
+	default:
+		return "AUTHORIZE"
+	}
+}
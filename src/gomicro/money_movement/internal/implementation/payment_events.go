@@ -0,0 +1,36 @@
+package mm
+
+import (
+	"github.com/sunr3d/gomicro/internal/producer"
+)
+
+// newPaymentEvent собирает данные перехода платежа p (уже в новом состоянии) для событий
+func newPaymentEvent(p payment, customerWallet wallet, shares []producer.MerchantShare) producer.PaymentEvent {
+	return producer.PaymentEvent{
+		Pid:       p.pid,
+		Status:    string(p.status),
+		Currency:  p.currency,
+		Customer:  producer.Party{UserID: customerWallet.userID, WalletID: customerWallet.ID},
+		Merchants: shares,
+	}
+}
+
+// splitShares возвращает части перехода по долям продавцов: amount(s) - сумма перехода
+// для доли s. Доли с нулевой суммой пропускаются
+func splitShares(tx UnitOfWork, splits []paymentSplit, amount func(s paymentSplit) int64) ([]producer.MerchantShare, error) {
+	var shares []producer.MerchantShare
+	for _, s := range splits {
+		if amount(s) == 0 {
+			continue
+		}
+		merchantWallet, err := tx.fetchWalletWithWalletID(s.merchantWalletID)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, producer.MerchantShare{
+			Merchant: producer.Party{UserID: merchantWallet.userID, WalletID: merchantWallet.ID},
+			Amount:   amount(s),
+		})
+	}
+	return shares, nil
+}
@@ -0,0 +1,513 @@
+package mm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sunr3d/gomicro/internal/currency"
+	"github.com/sunr3d/gomicro/internal/payout"
+	"github.com/sunr3d/gomicro/internal/producer"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log"
+	"time"
+)
+
+const payoutBatchSize = 100 // Выплаты в обработке перебираются страницами по id
+
+// Состояния выплаты
+const (
+	payoutStatusPending = "PENDING" // Средства зарезервированы на PAYOUT_PENDING, выплата в обработке у провайдера
+	payoutStatusSent    = "SENT"    // Деньги отправлены в банк продавца
+	payoutStatusFailed  = "FAILED"  // Банк отклонил выплату, средства возвращены на INCOMING
+)
+
+const payoutPendingAccountType = "PAYOUT_PENDING"
+
+// RequestPayout создает выплату продавцу на внешний банковский счет
+//
+// Сумма сразу резервируется: переводится со счета INCOMING на счет PAYOUT_PENDING.
+// Передачу выплаты провайдеру и обработку ее результата выполняет RunPayoutWorker.
+//
+// Основные шаги:
+//  1. Проверка валюты и суммы
+//  2. Начало SQL транзакции и проверка ключа идемпотентности
+//  3. Получение кошелька продавца и его счетов INCOMING и PAYOUT_PENDING
+//  4. Перевод средств на PAYOUT_PENDING и создание транзакции
+//  5. Создание выплаты в состоянии PENDING
+//  6. Запись события о запросе выплаты в outbox
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - payoutPayload: продавец, сумма и валюта выплаты
+//
+// Возвращает:
+//   - выплату
+//   - ошибку в случае неудачи
+func (this *Implementation) RequestPayout(ctx context.Context, payoutPayload *pb.RequestPayoutPayload) (*pb.Payout, error) {
+	// Проверка кода валюты и суммы
+	if err := currency.Validate(payoutPayload.GetCurrency()); err != nil {
+		return nil, err
+	}
+	if payoutPayload.GetCents() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be positive")
+	}
+
+	// Начало транзакции (включаем изолированный запрос)
+	tx, err := this.store.Begin()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Повторный запрос с тем же ключом идемпотентности не создает вторую выплату
+	response := &pb.Payout{}
+	replayed, err := claimIdempotencyKey(tx, operationPayout, payoutPayload.GetIdempotencyKey(), payoutPayload, response)
+	if err != nil || replayed {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+
+	// Получение кошелька продавца
+	merchantWallet, err := tx.fetchWallet(payoutPayload.MerchantUserId)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+	if merchantWallet.walletType != "MERCHANT" {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, status.Error(codes.FailedPrecondition, "payouts are available only for merchant wallets")
+	}
+
+	// Получение счета входящих платежей и счета резерва выплат продавца
+	srcAccount, err := tx.fetchAccount(merchantWallet.ID, "INCOMING", payoutPayload.Currency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+	dstAccount, err := tx.fetchAccount(merchantWallet.ID, payoutPendingAccountType, payoutPayload.Currency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("wallet has no %s account in %s", payoutPendingAccountType, payoutPayload.Currency))
+		}
+		return nil, err
+	}
+
+	// Резервирование суммы выплаты
+	err = transfer(tx, srcAccount, dstAccount, payoutPayload.Cents)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Создание транзакции резервирования
+	payoutID := uuid.NewString()
+	err = createTransaction(
+		tx,                  // БД
+		payoutID,            // айди транзакции
+		srcAccount,          // счет отправления
+		dstAccount,          // счет получения
+		merchantWallet,      // кошелек отправителя
+		merchantWallet,      // кошелек получателя
+		merchantWallet,      // конечный кошелек получателя
+		payoutPayload.Cents) // сумма
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Создание выплаты
+	err = tx.createPayout(payoutRecord{
+		ID:               payoutID,
+		merchantWalletID: merchantWallet.ID,
+		amount:           payoutPayload.Cents,
+		currency:         payoutPayload.Currency,
+		status:           payoutStatusPending,
+	})
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+	po, err := tx.fetchPayout(payoutID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Сохраняем ответ для повторов запроса с тем же ключом идемпотентности
+	response = payoutToProto(po, merchantWallet)
+	err = saveIdempotencyResponse(tx, operationPayout, payoutPayload.GetIdempotencyKey(), response)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Событие о запросе выплаты (outbox)
+	messages, err := producer.PayoutRequestedMessages(ctx, payoutID, merchantWallet.userID, payoutPayload.Cents, payoutPayload.Currency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	err = tx.insertOutboxMessages(messages)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Конец транзакции, коммит изменений в БД
+	err = tx.Commit()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return response, nil
+}
+
+// GetPayout возвращает текущее состояние выплаты
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - getPayoutPayload: идентификатор выплаты
+//
+// Возвращает:
+//   - выплату
+//   - ошибку в случае неудачи
+func (this *Implementation) GetPayout(ctx context.Context, getPayoutPayload *pb.GetPayoutPayload) (*pb.Payout, error) {
+	// Начало транзакции (все чтения выполняются по одному снимку данных)
+	tx, err := this.store.Begin()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer func() {
+		// Транзакция только читает данные, поэтому всегда откатывается
+		_ = tx.Rollback()
+	}()
+
+	po, err := tx.fetchPayout(getPayoutPayload.GetPayoutId())
+	if err != nil {
+		return nil, err
+	}
+	merchantWallet, err := tx.fetchWalletWithWalletID(po.merchantWalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	return payoutToProto(po, merchantWallet), nil
+}
+
+// RunPayoutWorker периодически передает выплаты провайдеру и обрабатывает их результат.
+// Блокирует вызывающую горутину до отмены ctx.
+//
+// Параметры:
+//   - ctx: контекст, отмена которого останавливает обработчик
+//   - interval: интервал между проходами
+func (this *Implementation) RunPayoutWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			completed, err := this.ProcessPayouts(ctx)
+			if err != nil {
+				log.Printf("payout worker: %v\n", err)
+			}
+			if completed > 0 {
+				log.Printf("payout worker: %d payouts completed\n", completed)
+			}
+		}
+	}
+}
+
+// ProcessPayouts проходит по всем выплатам в обработке: передает провайдеру новые
+// и завершает те, по которым провайдер сообщил результат
+//
+// Ошибки провайдера считаются временными и только логируются: выплата
+// будет обработана на следующем проходе. Ошибка завершения выплаты тоже не
+// останавливает проход, чтобы одна выплата не блокировала все следующие за ней.
+//
+// Возвращает:
+//   - количество завершенных выплат (SENT или FAILED)
+//   - объединенную ошибку незавершенных выплат и ошибку выборки
+func (this *Implementation) ProcessPayouts(ctx context.Context) (int, error) {
+	completed := 0
+	lastID := ""
+	var errs []error
+	for {
+		pending, err := this.fetchPendingPayouts(lastID)
+		if err != nil {
+			return completed, errors.Join(append(errs, err)...)
+		}
+
+		for _, p := range pending {
+			lastID = p.payout.ID
+
+			result, err := this.pollPayout(ctx, p)
+			if err != nil {
+				log.Printf("payout %s: %v\n", p.payout.ID, err)
+				continue
+			}
+			if result.Status == payout.StatusPending {
+				continue
+			}
+
+			ok, err := this.completePayout(ctx, p.payout.ID, result)
+			if err != nil {
+				log.Printf("payout %s: complete %s: %v\n", p.payout.ID, result.Status, err)
+				errs = append(errs, fmt.Errorf("payout %s: %w", p.payout.ID, err))
+				continue
+			}
+			if ok {
+				completed++
+			}
+		}
+
+		if len(pending) < payoutBatchSize || ctx.Err() != nil {
+			return completed, errors.Join(errs...)
+		}
+	}
+}
+
+// pendingPayout - выплата в обработке и ее идентификатор у провайдера (если уже передана)
+type pendingPayout struct {
+	payout    payout.Payout
+	reference sql.NullString
+}
+
+// fetchPendingPayouts возвращает очередную страницу выплат в обработке после afterID
+func (this *Implementation) fetchPendingPayouts(afterID string) ([]pendingPayout, error) {
+	tx, err := this.store.Begin()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer func() {
+		// Выборка только читает данные, поэтому всегда откатывается
+		_ = tx.Rollback()
+	}()
+
+	return tx.fetchPendingPayouts(afterID, payoutBatchSize)
+}
+
+// pollPayout передает выплату провайдеру (если она еще не передана) и возвращает ее состояние
+func (this *Implementation) pollPayout(ctx context.Context, p pendingPayout) (payout.Result, error) {
+	if !p.reference.Valid {
+		reference, err := this.payoutProvider.Submit(ctx, p.payout)
+		if err != nil {
+			return payout.Result{}, err
+		}
+
+		// Провайдер идемпотентен по id выплаты, поэтому повторная передача
+		// после сбоя между Submit и сохранением reference безопасна
+		err = this.savePayoutReference(p.payout.ID, reference)
+		if err != nil {
+			return payout.Result{}, err
+		}
+		p.reference = sql.NullString{String: reference, Valid: true}
+	}
+
+	return this.payoutProvider.Status(ctx, p.reference.String)
+}
+
+// savePayoutReference сохраняет идентификатор выплаты у провайдера, пока выплата в обработке
+func (this *Implementation) savePayoutReference(payoutID string, reference string) error {
+	tx, err := this.store.Begin()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	err = tx.setPayoutReference(payoutID, reference)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// completePayout завершает выплату по результату провайдера
+//
+// Основные шаги:
+//  1. Начало SQL транзакции и получение выплаты с блокировкой
+//  2. Перевод средств с PAYOUT_PENDING: при отправке - на клиринговый счет
+//     (деньги ушли из системы), при отказе - обратно на INCOMING продавца
+//  3. Создание транзакции и обновление состояния выплаты
+//  4. Запись события о результате выплаты в outbox
+//
+// Возвращает:
+//   - false, если выплата уже завершена (например, другой репликой)
+//   - ошибку в случае неудачи
+func (this *Implementation) completePayout(ctx context.Context, payoutID string, result payout.Result) (bool, error) {
+	// Начало транзакции (включаем изолированный запрос)
+	tx, err := this.store.Begin()
+	if err != nil {
+		return false, status.Error(codes.Internal, err.Error())
+	}
+
+	// Получение выплаты с блокировкой строки до конца транзакции
+	po, err := tx.lockPayout(payoutID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+	if po.status != payoutStatusPending {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, nil
+	}
+
+	// Получение кошелька продавца и счета резерва выплат
+	merchantWallet, err := tx.fetchWalletWithWalletID(po.merchantWalletID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+	srcAccount, err := tx.fetchAccount(merchantWallet.ID, payoutPendingAccountType, po.currency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+
+	// Счет получения зависит от результата выплаты
+	var (
+		dstWallet  wallet
+		dstAccount account
+	)
+	if result.Status == payout.StatusSent {
+		po.status = payoutStatusSent
+		dstWallet, dstAccount, err = fetchClearingAccount(tx, po.currency)
+	} else {
+		po.status = payoutStatusFailed
+		po.failureReason = sql.NullString{String: result.Reason, Valid: true}
+		dstWallet = merchantWallet
+		dstAccount, err = tx.fetchAccount(merchantWallet.ID, "INCOMING", po.currency)
+	}
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+
+	// Перевод средств с резерва выплат
+	err = transfer(tx, srcAccount, dstAccount, po.amount)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+
+	// Создание транзакции завершения выплаты
+	err = createTransaction(
+		tx,             // БД
+		po.ID,          // айди транзакции
+		srcAccount,     // счет отправления
+		dstAccount,     // счет получения
+		merchantWallet, // кошелек отправителя
+		dstWallet,      // кошелек получателя
+		dstWallet,      // конечный кошелек получателя
+		po.amount)      // сумма
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+
+	// Обновление состояния выплаты
+	err = tx.updatePayout(po)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+
+	// Событие о результате выплаты (outbox)
+	var messages []producer.Message
+	if po.status == payoutStatusSent {
+		messages, err = producer.PayoutSentMessages(ctx, po.ID, merchantWallet.userID, po.amount, po.currency)
+	} else {
+		messages, err = producer.PayoutFailedMessages(ctx, po.ID, merchantWallet.userID, po.amount, po.currency)
+	}
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, status.Error(codes.Internal, err.Error())
+	}
+	err = tx.insertOutboxMessages(messages)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return false, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return false, err
+	}
+
+	// Запись транзакции в БД
+	err = tx.Commit()
+	if err != nil {
+		return false, status.Error(codes.Internal, err.Error())
+	}
+
+	return true, nil
+}
+
+func payoutToProto(p payoutRecord, merchantWallet wallet) *pb.Payout {
+	return &pb.Payout{
+		PayoutId:       p.ID,
+		MerchantUserId: merchantWallet.userID,
+		Cents:          p.amount,
+		Currency:       p.currency,
+		Status:         p.status,
+		FailureReason:  p.failureReason.String,
+		CreatedAt:      timestamppb.New(p.createdAt),
+		UpdatedAt:      timestamppb.New(p.updatedAt),
+	}
+}
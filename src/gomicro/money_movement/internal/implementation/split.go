@@ -0,0 +1,155 @@
+package mm
+
+import (
+	"fmt"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// resolveSplits определяет доли продавцов в авторизуемом платеже
+//
+// Платеж маркетплейса задает доли в authorizePayload.Splits, обычный платеж -
+// одного продавца в merchantWalletUserID (он получает всю сумму).
+// Каждый продавец должен принимать платежи в валюте платежа.
+//
+// Возвращает:
+//   - доли продавцов (без pid) и их кошельки в том же порядке
+//   - ошибку в случае неудачи
+func resolveSplits(tx UnitOfWork, authorizePayload *pb.AuthorizePayload) ([]paymentSplit, []wallet, error) {
+	requested := authorizePayload.GetSplits()
+	if len(requested) == 0 {
+		requested = []*pb.MerchantSplit{{
+			MerchantWalletUserId: authorizePayload.MerchantWalletUserID,
+			Cents:                authorizePayload.Cents,
+		}}
+	} else if authorizePayload.GetMerchantWalletUserID() != "" {
+		return nil, nil, status.Error(codes.InvalidArgument, "merchantWalletUserID and splits are mutually exclusive")
+	}
+
+	// Проверка долей: положительные суммы, без повторов продавцов, в сумме - весь платеж
+	var total int64
+	seen := make(map[string]bool, len(requested))
+	for _, s := range requested {
+		if s.GetCents() <= 0 {
+			return nil, nil, status.Error(codes.InvalidArgument, "split amount must be positive")
+		}
+		if seen[s.GetMerchantWalletUserId()] {
+			return nil, nil, status.Error(codes.InvalidArgument, fmt.Sprintf("duplicate split for merchant %s", s.GetMerchantWalletUserId()))
+		}
+		seen[s.GetMerchantWalletUserId()] = true
+		total += s.Cents
+	}
+	if total != authorizePayload.Cents {
+		return nil, nil, status.Error(codes.InvalidArgument, "splits must add up to the payment amount")
+	}
+
+	splits := make([]paymentSplit, 0, len(requested))
+	wallets := make([]wallet, 0, len(requested))
+	for _, s := range requested {
+		// Получаем кошелек продавца
+		merchantWallet, err := tx.fetchWallet(s.MerchantWalletUserId)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Проверяем, что продавец принимает платежи в валюте платежа
+		_, err = tx.fetchAccount(merchantWallet.ID, "INCOMING", authorizePayload.Currency)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return nil, nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("merchant %s does not accept %s", merchantWallet.userID, authorizePayload.Currency))
+			}
+			return nil, nil, err
+		}
+
+		splits = append(splits, paymentSplit{merchantWalletID: merchantWallet.ID, amount: s.Cents})
+		wallets = append(wallets, merchantWallet)
+	}
+	return splits, wallets, nil
+}
+
+// selectSplit выбирает долю продавца merchantUserID. Если продавец не указан,
+// выбирается единственная доля платежа, а при нескольких долях - ошибка
+//
+// Возвращает:
+//   - индекс доли в splits
+//   - ошибку в случае неудачи
+func selectSplit(tx UnitOfWork, splits []paymentSplit, merchantUserID string) (int, error) {
+	if merchantUserID == "" {
+		if len(splits) > 1 {
+			return 0, status.Error(codes.InvalidArgument, "merchant_wallet_user_id is required for a payment with several merchants")
+		}
+		return 0, nil
+	}
+
+	merchantWallet, err := tx.fetchWallet(merchantUserID)
+	if err != nil {
+		return 0, err
+	}
+	for i, s := range splits {
+		if s.merchantWalletID == merchantWallet.ID {
+			return i, nil
+		}
+	}
+	return 0, status.Error(codes.InvalidArgument, fmt.Sprintf("merchant %s is not a party of the payment", merchantUserID))
+}
+
+// splitCapture - часть подтверждения, приходящаяся на долю одного продавца
+type splitCapture struct {
+	split          *paymentSplit
+	amount         int64 // Подтверждаемая сумма в валюте платежа
+	merchantWallet wallet
+	account        account // Счет входящих платежей продавца
+	plan           feePlan
+	fee            int64 // Комиссия платформы с amount
+}
+
+// planCapture распределяет подтверждение по долям продавцов
+//
+// С указанным продавцом подтверждается только его доля (cents = 0 - весь ее остаток).
+// Без продавца подтверждается весь неподтвержденный остаток всех долей; частичное
+// подтверждение без указания продавца возможно только для платежа с одним продавцом.
+//
+// Возвращает:
+//   - части подтверждения (ссылаются на элементы splits)
+//   - ошибку в случае неудачи
+func planCapture(tx UnitOfWork, splits []paymentSplit, merchantUserID string, cents int64) ([]splitCapture, error) {
+	// Подтверждение доли одного продавца
+	if merchantUserID != "" || len(splits) == 1 {
+		i, err := selectSplit(tx, splits, merchantUserID)
+		if err != nil {
+			return nil, err
+		}
+		remaining := splits[i].amount - splits[i].capturedAmount
+		if cents == 0 {
+			cents = remaining
+		}
+		if remaining == 0 {
+			return nil, status.Error(codes.FailedPrecondition, "merchant share already captured")
+		}
+		if cents > remaining {
+			return nil, status.Error(codes.FailedPrecondition, "capture amount exceeds remaining authorized amount")
+		}
+		return []splitCapture{{split: &splits[i], amount: cents}}, nil
+	}
+
+	// Подтверждение остатка всех долей
+	var remaining int64
+	for _, s := range splits {
+		remaining += s.amount - s.capturedAmount
+	}
+	if cents > remaining {
+		return nil, status.Error(codes.FailedPrecondition, "capture amount exceeds remaining authorized amount")
+	}
+	if cents != 0 && cents != remaining {
+		return nil, status.Error(codes.InvalidArgument, "merchant_wallet_user_id is required for a partial capture of a payment with several merchants")
+	}
+
+	var parts []splitCapture
+	for i := range splits {
+		if amount := splits[i].amount - splits[i].capturedAmount; amount > 0 {
+			parts = append(parts, splitCapture{split: &splits[i], amount: amount})
+		}
+	}
+	return parts, nil
+}
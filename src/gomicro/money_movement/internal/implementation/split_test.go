@@ -0,0 +1,201 @@
+package mm
+
+import (
+	"context"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+// authorizeMemorySplit авторизует платеж маркетплейса с долями cents продавцов merchantIDs
+func authorizeMemorySplit(t *testing.T, impl *Implementation, customerID string, merchantIDs []string, cents []int64) string {
+	t.Helper()
+
+	payload := &pb.AuthorizePayload{CustomerWalletUserID: customerID, Currency: "USD"}
+	for i, merchantID := range merchantIDs {
+		payload.Splits = append(payload.Splits, &pb.MerchantSplit{MerchantWalletUserId: merchantID, Cents: cents[i]})
+		payload.Cents += cents[i]
+	}
+	resp, err := impl.Authorize(context.Background(), payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Pid
+}
+
+// memorySplits возвращает доли платежа pid по продавцам
+func memorySplits(t *testing.T, impl *Implementation, pid string) map[string]*pb.PaymentSplit {
+	t.Helper()
+
+	p, err := impl.GetPayment(context.Background(), &pb.GetPaymentPayload{Pid: pid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	splits := map[string]*pb.PaymentSplit{}
+	for _, s := range p.Splits {
+		splits[s.MerchantWalletUserId] = s
+	}
+	return splits
+}
+
+func TestMemorySplitAuthorizeCaptureRefund(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	ctx := context.Background()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	first := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+	second := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	pid := authorizeMemorySplit(t, impl, customerID, []string{first, second}, []int64{600, 400})
+	if got := memoryBalance(t, impl, customerID, "PAYMENT"); got != 1000 {
+		t.Errorf("customer PAYMENT = %d, want 1000", got)
+	}
+
+	// Подтверждение без продавца подтверждает доли всех продавцов
+	if _, err := impl.Capture(ctx, &pb.CapturePayload{Pid: pid}); err != nil {
+		t.Fatal(err)
+	}
+	if got := memoryBalance(t, impl, first, "INCOMING"); got != 600 {
+		t.Errorf("first merchant INCOMING = %d, want 600", got)
+	}
+	if got := memoryBalance(t, impl, second, "INCOMING"); got != 400 {
+		t.Errorf("second merchant INCOMING = %d, want 400", got)
+	}
+
+	// Возврат платежа с несколькими продавцами требует указать продавца
+	_, err := impl.Refund(ctx, &pb.RefundPayload{Pid: pid, Cents: 100})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("refund without merchant: expected InvalidArgument, got %v", err)
+	}
+
+	// Возврат по доле второго продавца не затрагивает первого
+	if _, err = impl.Refund(ctx, &pb.RefundPayload{Pid: pid, Cents: 150, MerchantWalletUserId: second}); err != nil {
+		t.Fatal(err)
+	}
+	if got := memoryBalance(t, impl, second, "INCOMING"); got != 250 {
+		t.Errorf("second merchant INCOMING = %d, want 250", got)
+	}
+	if got := memoryBalance(t, impl, first, "INCOMING"); got != 600 {
+		t.Errorf("first merchant INCOMING = %d, want 600", got)
+	}
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 9150 {
+		t.Errorf("customer DEFAULT = %d, want 9150", got)
+	}
+	splits := memorySplits(t, impl, pid)
+	if got := splits[second].RefundedCents; got != 150 {
+		t.Errorf("second split refunded = %d, want 150", got)
+	}
+	if got := splits[first].RefundedCents; got != 0 {
+		t.Errorf("first split refunded = %d, want 0", got)
+	}
+
+	// Вернуть больше оставшейся доли продавца нельзя, даже если платеж в целом это позволяет
+	_, err = impl.Refund(ctx, &pb.RefundPayload{Pid: pid, Cents: 300, MerchantWalletUserId: second})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("refund above merchant share: expected FailedPrecondition, got %v", err)
+	}
+	if got := store.state.payments[pid].status; got != statusCaptured {
+		t.Errorf("status = %s, want %s", got, statusCaptured)
+	}
+}
+
+func TestMemorySplitCaptureByMerchant(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	ctx := context.Background()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	first := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+	second := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	pid := authorizeMemorySplit(t, impl, customerID, []string{first, second}, []int64{600, 400})
+
+	// Без суммы подтверждается весь остаток доли указанного продавца
+	if _, err := impl.Capture(ctx, &pb.CapturePayload{Pid: pid, MerchantWalletUserId: first}); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.state.payments[pid].status; got != statusPartiallyCaptured {
+		t.Errorf("status = %s, want %s", got, statusPartiallyCaptured)
+	}
+	if got := memoryBalance(t, impl, second, "INCOMING"); got != 0 {
+		t.Errorf("second merchant INCOMING = %d, want 0", got)
+	}
+
+	// Частичное подтверждение без продавца неоднозначно
+	_, err := impl.Capture(ctx, &pb.CapturePayload{Pid: pid, Cents: 100})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("partial capture without merchant: expected InvalidArgument, got %v", err)
+	}
+	_, err = impl.Capture(ctx, &pb.CapturePayload{Pid: pid, MerchantWalletUserId: first})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("captured share: expected FailedPrecondition, got %v", err)
+	}
+
+	// Подтверждение без продавца забирает остаток оставшихся долей
+	if _, err = impl.Capture(ctx, &pb.CapturePayload{Pid: pid}); err != nil {
+		t.Fatal(err)
+	}
+	if got := memoryBalance(t, impl, first, "INCOMING"); got != 600 {
+		t.Errorf("first merchant INCOMING = %d, want 600", got)
+	}
+	if got := memoryBalance(t, impl, second, "INCOMING"); got != 400 {
+		t.Errorf("second merchant INCOMING = %d, want 400", got)
+	}
+	if got := store.state.payments[pid].status; got != statusCaptured {
+		t.Errorf("status = %s, want %s", got, statusCaptured)
+	}
+}
+
+func TestMemorySplitValidation(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	first := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+	second := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	tests := []struct {
+		name    string
+		payload *pb.AuthorizePayload
+	}{
+		{
+			name: "splits do not add up",
+			payload: &pb.AuthorizePayload{Cents: 1000, Splits: []*pb.MerchantSplit{
+				{MerchantWalletUserId: first, Cents: 600},
+				{MerchantWalletUserId: second, Cents: 300},
+			}},
+		},
+		{
+			name: "duplicate merchant",
+			payload: &pb.AuthorizePayload{Cents: 1000, Splits: []*pb.MerchantSplit{
+				{MerchantWalletUserId: first, Cents: 500},
+				{MerchantWalletUserId: first, Cents: 500},
+			}},
+		},
+		{
+			name: "zero split",
+			payload: &pb.AuthorizePayload{Cents: 1000, Splits: []*pb.MerchantSplit{
+				{MerchantWalletUserId: first, Cents: 1000},
+				{MerchantWalletUserId: second, Cents: 0},
+			}},
+		},
+		{
+			name: "merchant and splits",
+			payload: &pb.AuthorizePayload{Cents: 1000, MerchantWalletUserID: first, Splits: []*pb.MerchantSplit{
+				{MerchantWalletUserId: first, Cents: 600},
+				{MerchantWalletUserId: second, Cents: 400},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.payload.CustomerWalletUserID = customerID
+			tt.payload.Currency = "USD"
+			_, err := impl.Authorize(context.Background(), tt.payload)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+
+	// Отклоненные авторизации ничего не удерживают
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 10000 {
+		t.Errorf("customer DEFAULT = %d, want 10000", got)
+	}
+}
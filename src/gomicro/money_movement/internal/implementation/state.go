@@ -0,0 +1,66 @@
+package mm
+
+import (
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// paymentStatus - состояние платежа, хранится в колонке payment.status
+type paymentStatus string
+
+const (
+	statusAuthorized        paymentStatus = "AUTHORIZED"         // Средства удержаны на расчетном счете покупателя
+	statusPartiallyCaptured paymentStatus = "PARTIALLY_CAPTURED" // Часть удержания подтверждена, остаток удерживается
+	statusCaptured          paymentStatus = "CAPTURED"           // Подтверждение завершено, удержание снято
+	statusVoided            paymentStatus = "VOIDED"             // Авторизация отменена, средства возвращены покупателю
+	statusRefunded          paymentStatus = "REFUNDED"           // Вся подтвержденная сумма возвращена покупателю
+	statusExpired           paymentStatus = "EXPIRED"            // Авторизация истекла, средства возвращены покупателю
+)
+
+// paymentEvent - операция над платежом, переводящая его в новое состояние
+type paymentEvent string
+
+const (
+	eventCapturePartial paymentEvent = "capture partially" // Подтверждение части удержания
+	eventCaptureFull    paymentEvent = "capture"           // Подтверждение, закрывающее удержание
+	eventVoid           paymentEvent = "void"              // Отмена авторизации
+	eventRefundPartial  paymentEvent = "refund partially"  // Возврат части подтвержденной суммы
+	eventRefundFull     paymentEvent = "refund"            // Возврат всей оставшейся подтвержденной суммы
+	eventExpire         paymentEvent = "expire"            // Истечение срока авторизации
+)
+
+// paymentTransitions описывает допустимые переходы между состояниями платежа.
+// Операции, отсутствующие в таблице для текущего состояния, запрещены
+var paymentTransitions = map[paymentStatus]map[paymentEvent]paymentStatus{
+	statusAuthorized: {
+		eventCapturePartial: statusPartiallyCaptured,
+		eventCaptureFull:    statusCaptured,
+		eventVoid:           statusVoided,
+		eventExpire:         statusExpired,
+	},
+	statusPartiallyCaptured: {
+		eventCapturePartial: statusPartiallyCaptured,
+		eventCaptureFull:    statusCaptured,
+		eventRefundPartial:  statusPartiallyCaptured, // Удержание остатка сохраняется до финального подтверждения
+		eventRefundFull:     statusPartiallyCaptured,
+		eventExpire:         statusCaptured, // Неподтвержденный остаток возвращается покупателю, подтвержденная часть остается у продавцов
+	},
+	statusCaptured: {
+		eventRefundPartial: statusCaptured,
+		eventRefundFull:    statusRefunded,
+	},
+}
+
+// nextPaymentStatus возвращает состояние, в которое переходит платеж после операции event
+//
+// Возвращает:
+//   - новое состояние платежа
+//   - ошибку FailedPrecondition, если операция недопустима в текущем состоянии
+func nextPaymentStatus(current paymentStatus, event paymentEvent) (paymentStatus, error) {
+	next, ok := paymentTransitions[current][event]
+	if !ok {
+		return current, status.Error(codes.FailedPrecondition, fmt.Sprintf("cannot %s payment in status %s", event, current))
+	}
+	return next, nil
+}
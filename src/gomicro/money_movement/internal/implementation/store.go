@@ -0,0 +1,85 @@
+package mm
+
+import (
+	"database/sql"
+	"github.com/sunr3d/gomicro/internal/producer"
+	"time"
+)
+
+// Store - хранилище данных сервиса перемещения денег.
+// Все чтения и изменения выполняются внутри единицы работы (UnitOfWork)
+//
+// Реализации:
+//   - NewMySQLStore: MySQL, схема из init.sql
+//   - NewMemoryStore: данные в памяти процесса (для тестов и локального запуска без БД)
+type Store interface {
+	// Begin начинает новую единицу работы
+	Begin() (UnitOfWork, error)
+}
+
+// UnitOfWork - единица работы с хранилищем (аналог SQL транзакции)
+//
+// Изменения, сделанные через единицу работы, применяются все вместе при Commit
+// или отбрасываются при Rollback. Строки, прочитанные методами lock*, остаются
+// заблокированными для других единиц работы до ее завершения.
+// Ошибки методов уже обернуты в gRPC статусы (NotFound, Internal и т.д.)
+type UnitOfWork interface {
+	Commit() error
+	Rollback() error
+
+	// Кошельки и счета
+	fetchWallet(userID string) (wallet, error)
+	fetchWalletWithWalletID(walletID int32) (wallet, error)
+	createWallet(userID string, walletType string) (int32, error) // AlreadyExists, если кошелек пользователя уже есть
+	createAccount(walletID int32, accountType string, currencyCode string) error
+	ensureAccount(walletID int32, accountType string, currencyCode string) error // Создает счет, если его еще нет
+	fetchAccount(walletID int32, accountType string, currencyCode string) (account, error)
+	fetchWalletAccounts(walletID int32) ([]account, error)
+	lockAccounts(accounts ...account) error
+	debitAccount(accountID int32, amount int64, allowOverdraft bool) error // Aborted, если не хватает средств
+	creditAccount(accountID int32, amount int64) error
+
+	// Транзакции
+	insertTransaction(pid string, srcAccount account, dstAccount account, srcWallet wallet, dstWallet wallet, finalDstWallet wallet, amount int64, quote *fxQuote, memo sql.NullString) error
+	fetchTransactions(pid string) ([]transaction, error)
+
+	// Платежи и доли продавцов
+	createPayment(p payment, ttl time.Duration) error
+	fetchPayment(pid string) (payment, error)
+	lockPayment(pid string) (payment, error)
+	updatePayment(p payment) error
+	fetchExpiredPayments(limit int) ([]string, error) // Авторизованные и частично подтвержденные платежи с истекшим сроком
+	createPaymentSplits(pid string, splits []paymentSplit) error
+	fetchPaymentSplits(pid string) ([]paymentSplit, error)
+	updatePaymentSplit(s paymentSplit) error
+
+	// Ключи идемпотентности
+	insertIdempotencyKey(operation string, key string, fingerprint string) (bool, error) // false, если ключ уже существует
+	fetchIdempotencyKey(operation string, key string) (string, sql.Null[[]byte], error)
+	updateIdempotencyResponse(operation string, key string, response []byte) error
+
+	// Курсы валют и котировки
+	upsertFxRate(baseCurrency string, quoteCurrency string, rate string) error
+	fetchFxRateValue(baseCurrency string, quoteCurrency string) (string, error)
+	createFxQuote(q fxQuote, ttl time.Duration) error
+	fetchFxQuote(id string) (fxQuote, error)
+	lockFxQuote(id string) (fxQuote, error)
+	setFxQuotePid(id string, pid string) error
+
+	// Комиссии
+	fetchFeePlan(merchantWalletID int32, currencyCode string) (feePlan, error)
+
+	// Выплаты
+	createPayout(p payoutRecord) error
+	fetchPayout(payoutID string) (payoutRecord, error)
+	lockPayout(payoutID string) (payoutRecord, error)
+	updatePayout(p payoutRecord) error
+	setPayoutReference(payoutID string, reference string) error // Только для выплат в обработке
+	fetchPendingPayouts(afterID string, limit int) ([]pendingPayout, error)
+
+	// Исходящие события (outbox)
+	insertOutboxMessages(messages []producer.Message) error
+	fetchPendingOutboxMessages(limit int) ([]outboxMessage, error) // Неотправленные события в порядке записи
+	markOutboxMessageSent(id int64) error
+	markOutboxMessageFailed(id int64, reason string) error // Увеличивает счетчик попыток
+}
@@ -0,0 +1,542 @@
+package mm
+
+import (
+	"cmp"
+	"database/sql"
+	"fmt"
+	"github.com/sunr3d/gomicro/internal/producer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// memorySystemWallets - системные кошельки, которые в MySQL создает init.sql.
+// Их счета создаются при первом обращении (кроме счетов обменного пункта)
+var memorySystemWallets = []string{fxDeskUserID, clearingUserID, platformUserID}
+
+// memoryStore - хранилище в памяти процесса
+//
+// Единицы работы выполняются строго по очереди: Begin захватывает мьютекс хранилища
+// до Commit/Rollback и работает с копией данных, которая при Commit заменяет текущие.
+// Поэтому блокировки lock* ничего не делают, а Rollback просто отбрасывает копию.
+// Данные не переживают перезапуск процесса, а вложенная единица работы в той же
+// горутине приводит к взаимоблокировке.
+type memoryStore struct {
+	mu    sync.Mutex
+	state *memoryState
+}
+
+type memoryState struct {
+	wallets           map[int32]wallet
+	accounts          map[int32]account
+	transactions      []transaction
+	payments          map[string]payment
+	paymentSplits     map[string][]paymentSplit
+	idempotencyKeys   map[memoryIdempotencyKey]memoryIdempotencyRecord
+	fxRates           map[[2]string]string // [base, quote] -> курс
+	fxQuotes          map[string]fxQuote
+	feePlans          map[memoryFeePlanKey]feePlan
+	payouts           map[string]payoutRecord
+	outbox            []outboxMessage // В порядке записи; отправленные события удаляются
+	lastWalletID      int32
+	lastAccountID     int32
+	lastTransactionID int32
+	lastOutboxID      int64
+}
+
+type memoryIdempotencyKey struct {
+	operation string
+	key       string
+}
+
+type memoryIdempotencyRecord struct {
+	fingerprint string
+	response    sql.Null[[]byte]
+}
+
+type memoryFeePlanKey struct {
+	merchantWalletID int32
+	currency         string
+}
+
+// NewMemoryStore создает пустое хранилище в памяти с системными кошельками
+// (обменный пункт, клиринг и платформа). Обменный пункт создается без счетов,
+// поэтому конвертация валют недоступна, пока их не пополнить
+//
+// Возвращает:
+//   - хранилище
+func NewMemoryStore() Store {
+	return newMemoryStore()
+}
+
+func newMemoryStore() *memoryStore {
+	state := &memoryState{
+		wallets:         map[int32]wallet{},
+		accounts:        map[int32]account{},
+		payments:        map[string]payment{},
+		paymentSplits:   map[string][]paymentSplit{},
+		idempotencyKeys: map[memoryIdempotencyKey]memoryIdempotencyRecord{},
+		fxRates:         map[[2]string]string{},
+		fxQuotes:        map[string]fxQuote{},
+		feePlans:        map[memoryFeePlanKey]feePlan{},
+		payouts:         map[string]payoutRecord{},
+	}
+	for _, userID := range memorySystemWallets {
+		state.lastWalletID++
+		state.wallets[state.lastWalletID] = wallet{ID: state.lastWalletID, userID: userID, walletType: "SYSTEM"}
+	}
+	return &memoryStore{state: state}
+}
+
+func (this *memoryStore) Begin() (UnitOfWork, error) {
+	this.mu.Lock()
+	return &memoryUnitOfWork{store: this, state: this.state.clone()}, nil
+}
+
+// clone копирует данные хранилища. Значения в картах не содержат изменяемых ссылок,
+// кроме долей платежей, которые копируются отдельно
+func (this *memoryState) clone() *memoryState {
+	c := *this
+	c.wallets = maps.Clone(this.wallets)
+	c.accounts = maps.Clone(this.accounts)
+	c.transactions = slices.Clone(this.transactions)
+	c.payments = maps.Clone(this.payments)
+	c.paymentSplits = make(map[string][]paymentSplit, len(this.paymentSplits))
+	for pid, splits := range this.paymentSplits {
+		c.paymentSplits[pid] = slices.Clone(splits)
+	}
+	c.idempotencyKeys = maps.Clone(this.idempotencyKeys)
+	c.fxRates = maps.Clone(this.fxRates)
+	c.fxQuotes = maps.Clone(this.fxQuotes)
+	c.feePlans = maps.Clone(this.feePlans)
+	c.payouts = maps.Clone(this.payouts)
+	c.outbox = slices.Clone(this.outbox)
+	return &c
+}
+
+// memoryUnitOfWork - единица работы над копией данных memoryStore
+type memoryUnitOfWork struct {
+	store *memoryStore
+	state *memoryState
+	done  bool
+}
+
+func (this *memoryUnitOfWork) Commit() error {
+	if this.done {
+		return sql.ErrTxDone
+	}
+	this.done = true
+	this.store.state = this.state
+	this.store.mu.Unlock()
+	return nil
+}
+
+func (this *memoryUnitOfWork) Rollback() error {
+	if this.done {
+		return sql.ErrTxDone
+	}
+	this.done = true
+	this.store.mu.Unlock()
+	return nil
+}
+
+func (this *memoryUnitOfWork) fetchWallet(userID string) (wallet, error) {
+	for _, w := range this.state.wallets {
+		if w.userID == userID {
+			return w, nil
+		}
+	}
+	return wallet{}, status.Error(codes.NotFound, "wallet not found")
+}
+
+func (this *memoryUnitOfWork) fetchWalletWithWalletID(walletID int32) (wallet, error) {
+	w, ok := this.state.wallets[walletID]
+	if !ok {
+		return wallet{}, status.Error(codes.NotFound, "wallet not found")
+	}
+	return w, nil
+}
+
+func (this *memoryUnitOfWork) createWallet(userID string, walletType string) (int32, error) {
+	if _, err := this.fetchWallet(userID); err == nil {
+		return 0, status.Error(codes.AlreadyExists, "wallet already exists")
+	}
+	this.state.lastWalletID++
+	this.state.wallets[this.state.lastWalletID] = wallet{ID: this.state.lastWalletID, userID: userID, walletType: walletType}
+	return this.state.lastWalletID, nil
+}
+
+func (this *memoryUnitOfWork) createAccount(walletID int32, accountType string, currencyCode string) error {
+	if _, err := this.fetchAccount(walletID, accountType, currencyCode); err == nil {
+		return status.Error(codes.Internal, fmt.Sprintf("%s account in %s already exists", accountType, currencyCode))
+	}
+	this.state.lastAccountID++
+	this.state.accounts[this.state.lastAccountID] = account{
+		ID:          this.state.lastAccountID,
+		accountType: accountType,
+		walletID:    walletID,
+		currency:    currencyCode,
+	}
+	return nil
+}
+
+func (this *memoryUnitOfWork) ensureAccount(walletID int32, accountType string, currencyCode string) error {
+	if _, err := this.fetchAccount(walletID, accountType, currencyCode); err == nil {
+		return nil
+	}
+	return this.createAccount(walletID, accountType, currencyCode)
+}
+
+func (this *memoryUnitOfWork) fetchAccount(walletID int32, accountType string, currencyCode string) (account, error) {
+	for _, a := range this.state.accounts {
+		if a.walletID == walletID && a.accountType == accountType && a.currency == currencyCode {
+			return a, nil
+		}
+	}
+	return account{}, status.Error(codes.NotFound, fmt.Sprintf("%s account in %s not found", accountType, currencyCode))
+}
+
+func (this *memoryUnitOfWork) fetchWalletAccounts(walletID int32) ([]account, error) {
+	var accounts []account
+	for _, a := range this.state.accounts {
+		if a.walletID == walletID {
+			accounts = append(accounts, a)
+		}
+	}
+	slices.SortFunc(accounts, func(a, b account) int {
+		return cmp.Or(cmp.Compare(a.currency, b.currency), cmp.Compare(a.accountType, b.accountType))
+	})
+	return accounts, nil
+}
+
+// lockAccounts только проверяет, что счета существуют: единица работы и так исключительная
+func (this *memoryUnitOfWork) lockAccounts(accounts ...account) error {
+	for _, a := range accounts {
+		if _, ok := this.state.accounts[a.ID]; !ok {
+			return status.Error(codes.NotFound, "account not found")
+		}
+	}
+	return nil
+}
+
+func (this *memoryUnitOfWork) debitAccount(accountID int32, amount int64, allowOverdraft bool) error {
+	a, ok := this.state.accounts[accountID]
+	if !ok || (!allowOverdraft && a.cents < amount) {
+		return status.Error(codes.Aborted, "not enough money")
+	}
+	a.cents -= amount
+	this.state.accounts[accountID] = a
+	return nil
+}
+
+func (this *memoryUnitOfWork) creditAccount(accountID int32, amount int64) error {
+	a, ok := this.state.accounts[accountID]
+	if !ok {
+		return status.Error(codes.NotFound, "account not found")
+	}
+	a.cents += amount
+	this.state.accounts[accountID] = a
+	return nil
+}
+
+func (this *memoryUnitOfWork) insertTransaction(pid string, srcAccount account, dstAccount account, srcWallet wallet, dstWallet wallet, finalDstWallet wallet, amount int64, quote *fxQuote, memo sql.NullString) error {
+	this.state.lastTransactionID++
+	t := transaction{
+		ID:                       this.state.lastTransactionID,
+		pid:                      pid,
+		srcUserID:                srcWallet.userID,
+		dstUserID:                dstWallet.userID,
+		srcAccountWalletID:       srcWallet.ID,
+		dstAccountWalletID:       dstWallet.ID,
+		srcAccountType:           srcAccount.accountType,
+		dstAccountType:           dstAccount.accountType,
+		finalDstMerchantWalletID: finalDstWallet.ID,
+		amount:                   amount,
+		currency:                 srcAccount.currency,
+		createdAt:                time.Now(),
+	}
+	if quote != nil {
+		t.fxQuoteID = sql.NullString{String: quote.ID, Valid: true}
+		t.fxRate = sql.NullString{String: quote.rate, Valid: true}
+	}
+	this.state.transactions = append(this.state.transactions, t)
+	return nil
+}
+
+func (this *memoryUnitOfWork) fetchTransactions(pid string) ([]transaction, error) {
+	var transactions []transaction
+	for _, t := range this.state.transactions {
+		if t.pid == pid {
+			transactions = append(transactions, t)
+		}
+	}
+	if len(transactions) == 0 {
+		return nil, status.Error(codes.NotFound, "payment not found")
+	}
+	return transactions, nil
+}
+
+func (this *memoryUnitOfWork) createPayment(p payment, ttl time.Duration) error {
+	if _, ok := this.state.payments[p.pid]; ok {
+		return status.Error(codes.Internal, fmt.Sprintf("payment %s already exists", p.pid))
+	}
+	now := time.Now()
+	p.status = statusAuthorized
+	p.createdAt = now
+	p.updatedAt = now
+	p.expiresAt = now.Add(ttl)
+	this.state.payments[p.pid] = p
+	return nil
+}
+
+func (this *memoryUnitOfWork) fetchPayment(pid string) (payment, error) {
+	p, ok := this.state.payments[pid]
+	if !ok {
+		return p, status.Error(codes.NotFound, "payment not found")
+	}
+	p.expired = !time.Now().Before(p.expiresAt)
+	return p, nil
+}
+
+func (this *memoryUnitOfWork) lockPayment(pid string) (payment, error) {
+	return this.fetchPayment(pid)
+}
+
+func (this *memoryUnitOfWork) updatePayment(p payment) error {
+	stored, ok := this.state.payments[p.pid]
+	if !ok {
+		return status.Error(codes.NotFound, "payment not found")
+	}
+	stored.capturedAmount = p.capturedAmount
+	stored.refundedAmount = p.refundedAmount
+	stored.status = p.status
+	stored.updatedAt = time.Now()
+	this.state.payments[p.pid] = stored
+	return nil
+}
+
+func (this *memoryUnitOfWork) fetchExpiredPayments(limit int) ([]string, error) {
+	now := time.Now()
+	var expired []payment
+	for _, p := range this.state.payments {
+		if (p.status == statusAuthorized || p.status == statusPartiallyCaptured) && !now.Before(p.expiresAt) {
+			expired = append(expired, p)
+		}
+	}
+	slices.SortFunc(expired, func(a, b payment) int {
+		return a.expiresAt.Compare(b.expiresAt)
+	})
+
+	pids := make([]string, 0, min(len(expired), limit))
+	for _, p := range expired[:min(len(expired), limit)] {
+		pids = append(pids, p.pid)
+	}
+	return pids, nil
+}
+
+func (this *memoryUnitOfWork) createPaymentSplits(pid string, splits []paymentSplit) error {
+	stored := make([]paymentSplit, 0, len(splits))
+	for _, s := range splits {
+		s.pid = pid
+		stored = append(stored, s)
+	}
+	this.state.paymentSplits[pid] = append(this.state.paymentSplits[pid], stored...)
+	return nil
+}
+
+func (this *memoryUnitOfWork) fetchPaymentSplits(pid string) ([]paymentSplit, error) {
+	splits := this.state.paymentSplits[pid]
+	if len(splits) == 0 {
+		return nil, status.Error(codes.Internal, fmt.Sprintf("payment %s has no merchant splits", pid))
+	}
+	return slices.Clone(splits), nil
+}
+
+func (this *memoryUnitOfWork) updatePaymentSplit(s paymentSplit) error {
+	splits := this.state.paymentSplits[s.pid]
+	for i := range splits {
+		if splits[i].merchantWalletID == s.merchantWalletID {
+			splits[i].capturedAmount = s.capturedAmount
+			splits[i].refundedAmount = s.refundedAmount
+			return nil
+		}
+	}
+	return status.Error(codes.NotFound, "payment split not found")
+}
+
+func (this *memoryUnitOfWork) insertIdempotencyKey(operation string, key string, fingerprint string) (bool, error) {
+	k := memoryIdempotencyKey{operation: operation, key: key}
+	if _, ok := this.state.idempotencyKeys[k]; ok {
+		return false, nil
+	}
+	this.state.idempotencyKeys[k] = memoryIdempotencyRecord{fingerprint: fingerprint}
+	return true, nil
+}
+
+func (this *memoryUnitOfWork) fetchIdempotencyKey(operation string, key string) (string, sql.Null[[]byte], error) {
+	record, ok := this.state.idempotencyKeys[memoryIdempotencyKey{operation: operation, key: key}]
+	if !ok {
+		return "", sql.Null[[]byte]{}, status.Error(codes.Internal, "idempotency key not found")
+	}
+	return record.fingerprint, record.response, nil
+}
+
+func (this *memoryUnitOfWork) updateIdempotencyResponse(operation string, key string, response []byte) error {
+	k := memoryIdempotencyKey{operation: operation, key: key}
+	record, ok := this.state.idempotencyKeys[k]
+	if !ok {
+		return status.Error(codes.Internal, "idempotency key not found")
+	}
+	record.response = sql.Null[[]byte]{V: slices.Clone(response), Valid: true}
+	this.state.idempotencyKeys[k] = record
+	return nil
+}
+
+func (this *memoryUnitOfWork) upsertFxRate(baseCurrency string, quoteCurrency string, rate string) error {
+	this.state.fxRates[[2]string{baseCurrency, quoteCurrency}] = rate
+	return nil
+}
+
+func (this *memoryUnitOfWork) fetchFxRateValue(baseCurrency string, quoteCurrency string) (string, error) {
+	rate, ok := this.state.fxRates[[2]string{baseCurrency, quoteCurrency}]
+	if !ok {
+		return "", status.Error(codes.NotFound, fmt.Sprintf("no fx rate for %s/%s", baseCurrency, quoteCurrency))
+	}
+	return rate, nil
+}
+
+func (this *memoryUnitOfWork) createFxQuote(q fxQuote, ttl time.Duration) error {
+	q.expiresAt = time.Now().Add(ttl)
+	q.pid = sql.NullString{}
+	this.state.fxQuotes[q.ID] = q
+	return nil
+}
+
+func (this *memoryUnitOfWork) fetchFxQuote(id string) (fxQuote, error) {
+	q, ok := this.state.fxQuotes[id]
+	if !ok {
+		return q, status.Error(codes.NotFound, "fx quote not found")
+	}
+	q.expired = !time.Now().Before(q.expiresAt)
+	return q, nil
+}
+
+func (this *memoryUnitOfWork) lockFxQuote(id string) (fxQuote, error) {
+	return this.fetchFxQuote(id)
+}
+
+func (this *memoryUnitOfWork) setFxQuotePid(id string, pid string) error {
+	q, ok := this.state.fxQuotes[id]
+	if !ok {
+		return status.Error(codes.NotFound, "fx quote not found")
+	}
+	q.pid = sql.NullString{String: pid, Valid: true}
+	this.state.fxQuotes[id] = q
+	return nil
+}
+
+func (this *memoryUnitOfWork) fetchFeePlan(merchantWalletID int32, currencyCode string) (feePlan, error) {
+	return this.state.feePlans[memoryFeePlanKey{merchantWalletID: merchantWalletID, currency: currencyCode}], nil
+}
+
+func (this *memoryUnitOfWork) createPayout(p payoutRecord) error {
+	if _, ok := this.state.payouts[p.ID]; ok {
+		return status.Error(codes.Internal, fmt.Sprintf("payout %s already exists", p.ID))
+	}
+	now := time.Now()
+	p.createdAt = now
+	p.updatedAt = now
+	this.state.payouts[p.ID] = p
+	return nil
+}
+
+func (this *memoryUnitOfWork) fetchPayout(payoutID string) (payoutRecord, error) {
+	p, ok := this.state.payouts[payoutID]
+	if !ok {
+		return p, status.Error(codes.NotFound, "payout not found")
+	}
+	return p, nil
+}
+
+func (this *memoryUnitOfWork) lockPayout(payoutID string) (payoutRecord, error) {
+	return this.fetchPayout(payoutID)
+}
+
+func (this *memoryUnitOfWork) updatePayout(p payoutRecord) error {
+	stored, ok := this.state.payouts[p.ID]
+	if !ok {
+		return status.Error(codes.NotFound, "payout not found")
+	}
+	stored.status = p.status
+	stored.failureReason = p.failureReason
+	stored.updatedAt = time.Now()
+	this.state.payouts[p.ID] = stored
+	return nil
+}
+
+func (this *memoryUnitOfWork) setPayoutReference(payoutID string, reference string) error {
+	p, ok := this.state.payouts[payoutID]
+	if !ok || p.status != payoutStatusPending {
+		return nil
+	}
+	p.providerReference = sql.NullString{String: reference, Valid: true}
+	p.updatedAt = time.Now()
+	this.state.payouts[payoutID] = p
+	return nil
+}
+
+func (this *memoryUnitOfWork) fetchPendingPayouts(afterID string, limit int) ([]pendingPayout, error) {
+	var pending []pendingPayout
+	for _, p := range this.state.payouts {
+		if p.status != payoutStatusPending || p.ID <= afterID {
+			continue
+		}
+		merchantWallet, err := this.fetchWalletWithWalletID(p.merchantWalletID)
+		if err != nil {
+			return nil, err
+		}
+
+		var next pendingPayout
+		next.payout.ID = p.ID
+		next.payout.MerchantUserID = merchantWallet.userID
+		next.payout.Amount = p.amount
+		next.payout.Currency = p.currency
+		next.reference = p.providerReference
+		pending = append(pending, next)
+	}
+	slices.SortFunc(pending, func(a, b pendingPayout) int {
+		return cmp.Compare(a.payout.ID, b.payout.ID)
+	})
+	return pending[:min(len(pending), limit)], nil
+}
+
+func (this *memoryUnitOfWork) insertOutboxMessages(messages []producer.Message) error {
+	for _, m := range messages {
+		this.state.lastOutboxID++
+		this.state.outbox = append(this.state.outbox, outboxMessage{ID: this.state.lastOutboxID, message: m})
+	}
+	return nil
+}
+
+func (this *memoryUnitOfWork) fetchPendingOutboxMessages(limit int) ([]outboxMessage, error) {
+	return slices.Clone(this.state.outbox[:min(len(this.state.outbox), limit)]), nil
+}
+
+func (this *memoryUnitOfWork) markOutboxMessageSent(id int64) error {
+	this.state.outbox = slices.DeleteFunc(this.state.outbox, func(m outboxMessage) bool {
+		return m.ID == id
+	})
+	return nil
+}
+
+func (this *memoryUnitOfWork) markOutboxMessageFailed(id int64, reason string) error {
+	for i := range this.state.outbox {
+		if this.state.outbox[i].ID == id {
+			this.state.outbox[i].attempts++
+		}
+	}
+	return nil
+}
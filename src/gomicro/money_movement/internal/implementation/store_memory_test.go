@@ -0,0 +1,1051 @@
+package mm
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sunr3d/gomicro/events"
+	"github.com/sunr3d/gomicro/internal/payout"
+	"github.com/sunr3d/gomicro/internal/producer"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"testing"
+	"time"
+)
+
+// Тесты бизнес-логики на хранилище в памяти: не требуют БД и запускаются всегда
+
+func newMemoryTestImplementation() (*Implementation, *memoryStore) {
+	store := newMemoryStore()
+	return NewMoneyMovementImplementation(store, time.Hour, time.Minute, 0, payout.NewFakeBank(0, 0), nil), store
+}
+
+// publisherFunc - продюсер Кафки для тестов отправки событий из outbox
+type publisherFunc func(messages []producer.Message) (int, error)
+
+func (this publisherFunc) Publish(messages []producer.Message) (int, error) {
+	return this(messages)
+}
+
+func (this publisherFunc) Close() error {
+	return nil
+}
+
+// createMemoryWallet создает кошелек в USD через CreateWallet и зачисляет cents на его
+// первый счет (DEFAULT у покупателя, INCOMING у продавца) напрямую в хранилище
+func createMemoryWallet(t *testing.T, impl *Implementation, store *memoryStore, walletType string, cents int64) string {
+	t.Helper()
+
+	userID := uuid.NewString()
+	_, err := impl.CreateWallet(context.Background(), &pb.CreateWalletPayload{UserId: userID, WalletType: walletType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cents == 0 {
+		return userID
+	}
+
+	tx, err := store.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tx.fetchWallet(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := tx.fetchAccount(w.ID, walletAccountTypes[walletType][0], "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.creditAccount(a.ID, cents); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return userID
+}
+
+// memoryBalance возвращает баланс USD счета accountType кошелька userID
+func memoryBalance(t *testing.T, impl *Implementation, userID string, accountType string) int64 {
+	t.Helper()
+
+	w, err := impl.GetWallet(context.Background(), &pb.GetWalletPayload{UserId: userID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range w.Accounts {
+		if a.AccountType == accountType && a.Currency == "USD" {
+			return a.Cents
+		}
+	}
+	t.Fatalf("%s has no USD %s account", userID, accountType)
+	return 0
+}
+
+func TestMemoryAuthorizeCapture(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	resp, err := impl.Authorize(context.Background(), &pb.AuthorizePayload{
+		CustomerWalletUserID: customerID,
+		MerchantWalletUserID: merchantID,
+		Cents:                1000,
+		Currency:             "USD",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 9000 {
+		t.Errorf("customer DEFAULT after authorize = %d, want 9000", got)
+	}
+	if got := memoryBalance(t, impl, customerID, "PAYMENT"); got != 1000 {
+		t.Errorf("customer PAYMENT after authorize = %d, want 1000", got)
+	}
+
+	if _, err = impl.Capture(context.Background(), &pb.CapturePayload{Pid: resp.Pid}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := memoryBalance(t, impl, customerID, "PAYMENT"); got != 0 {
+		t.Errorf("customer PAYMENT after capture = %d, want 0", got)
+	}
+	if got := memoryBalance(t, impl, merchantID, "INCOMING"); got != 1000 {
+		t.Errorf("merchant INCOMING after capture = %d, want 1000", got)
+	}
+
+	_, err = impl.Capture(context.Background(), &pb.CapturePayload{Pid: resp.Pid})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("second capture: expected FailedPrecondition, got %v", err)
+	}
+}
+
+func TestMemoryCaptureFee(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	// Тариф 2.9% + 30 центов
+	tx, err := store.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	merchantWallet, err := tx.fetchWallet(merchantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	store.state.feePlans[memoryFeePlanKey{merchantWalletID: merchantWallet.ID, currency: "USD"}] = feePlan{percentBps: 290, fixedCents: 30}
+
+	resp, err := impl.Authorize(context.Background(), &pb.AuthorizePayload{
+		CustomerWalletUserID: customerID,
+		MerchantWalletUserID: merchantID,
+		Cents:                1000,
+		Currency:             "USD",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = impl.Capture(context.Background(), &pb.CapturePayload{Pid: resp.Pid}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := memoryBalance(t, impl, merchantID, "INCOMING"); got != 941 {
+		t.Errorf("merchant INCOMING = %d, want 941", got)
+	}
+	if got := memoryBalance(t, impl, platformUserID, revenueAccountType); got != 59 {
+		t.Errorf("platform REVENUE = %d, want 59", got)
+	}
+}
+
+func TestMemoryRefundReturnsFee(t *testing.T) {
+	tests := []struct {
+		name    string
+		refunds []int64
+	}{
+		{name: "full", refunds: []int64{1000}},
+		{name: "partial then remainder", refunds: []int64{333, 667}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl, store := newMemoryTestImplementation()
+			customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+			merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+			// Тариф 2.9% + 30 центов
+			tx, err := store.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			merchantWallet, err := tx.fetchWallet(merchantID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = tx.Rollback(); err != nil {
+				t.Fatal(err)
+			}
+			store.state.feePlans[memoryFeePlanKey{merchantWalletID: merchantWallet.ID, currency: "USD"}] = feePlan{percentBps: 290, fixedCents: 30}
+
+			resp, err := impl.Authorize(context.Background(), &pb.AuthorizePayload{
+				CustomerWalletUserID: customerID,
+				MerchantWalletUserID: merchantID,
+				Cents:                1000,
+				Currency:             "USD",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = impl.Capture(context.Background(), &pb.CapturePayload{Pid: resp.Pid}); err != nil {
+				t.Fatal(err)
+			}
+			for _, cents := range tt.refunds {
+				if _, err = impl.Refund(context.Background(), &pb.RefundPayload{Pid: resp.Pid, Cents: cents}); err != nil {
+					t.Fatalf("refund %d: %v", cents, err)
+				}
+			}
+
+			// Комиссия возвращена продавцу, все балансы вернулись к исходным
+			if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 10000 {
+				t.Errorf("customer DEFAULT = %d, want 10000", got)
+			}
+			if got := memoryBalance(t, impl, customerID, "PAYMENT"); got != 0 {
+				t.Errorf("customer PAYMENT = %d, want 0", got)
+			}
+			if got := memoryBalance(t, impl, merchantID, "INCOMING"); got != 0 {
+				t.Errorf("merchant INCOMING = %d, want 0", got)
+			}
+			if got := memoryBalance(t, impl, platformUserID, revenueAccountType); got != 0 {
+				t.Errorf("platform REVENUE = %d, want 0", got)
+			}
+		})
+	}
+}
+
+func TestMemoryAuthorizeNotEnoughMoney(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 500)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	_, err := impl.Authorize(context.Background(), &pb.AuthorizePayload{
+		CustomerWalletUserID: customerID,
+		MerchantWalletUserID: merchantID,
+		Cents:                1000,
+		Currency:             "USD",
+		IdempotencyKey:       "not-enough-money",
+	})
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("expected Aborted, got %v", err)
+	}
+
+	// Откат единицы работы отбрасывает все ее изменения
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 500 {
+		t.Errorf("customer DEFAULT = %d, want 500", got)
+	}
+	if len(store.state.payments) != 0 || len(store.state.idempotencyKeys) != 0 {
+		t.Errorf("rolled back authorization left %d payments and %d idempotency keys", len(store.state.payments), len(store.state.idempotencyKeys))
+	}
+}
+
+func TestMemoryAuthorizeIdempotent(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	payload := &pb.AuthorizePayload{
+		CustomerWalletUserID: customerID,
+		MerchantWalletUserID: merchantID,
+		Cents:                1000,
+		Currency:             "USD",
+		IdempotencyKey:       uuid.NewString(),
+	}
+	first, err := impl.Authorize(context.Background(), payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := impl.Authorize(context.Background(), payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Pid != second.Pid {
+		t.Errorf("replayed authorize returned pid %s, want %s", second.Pid, first.Pid)
+	}
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 9000 {
+		t.Errorf("customer DEFAULT = %d, want 9000", got)
+	}
+}
+
+func TestMemoryOutboxRelay(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	resp, err := impl.Authorize(context.Background(), &pb.AuthorizePayload{
+		CustomerWalletUserID: customerID,
+		MerchantWalletUserID: merchantID,
+		Cents:                1000,
+		Currency:             "USD",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = impl.Capture(context.Background(), &pb.CapturePayload{Pid: resp.Pid}); err != nil {
+		t.Fatal(err)
+	}
+
+	// События подтверждения ждут отправки в outbox
+	pending := len(store.state.outbox)
+	if pending == 0 {
+		t.Fatal("capture wrote no outbox messages")
+	}
+	for _, m := range store.state.outbox {
+		if m.message.Key != resp.Pid {
+			t.Errorf("outbox message key = %q, want %q", m.message.Key, resp.Pid)
+		}
+	}
+
+	// Кафка недоступна после первого события: оно отмечается отправленным,
+	// следующее остается в outbox с неудачной попыткой
+	var published []producer.Message
+	impl.publisher = publisherFunc(func(messages []producer.Message) (int, error) {
+		published = append(published, messages[0])
+		return 1, errors.New("kafka is down")
+	})
+	sent, err := impl.RelayOutbox(context.Background())
+	if err == nil || sent != 1 {
+		t.Fatalf("relay with failing kafka: sent %d, err %v", sent, err)
+	}
+	if len(store.state.outbox) != pending-1 || store.state.outbox[0].attempts != 1 {
+		t.Fatalf("after failed relay: %d pending, first has %d attempts", len(store.state.outbox), store.state.outbox[0].attempts)
+	}
+
+	// После восстановления отправляется остаток в исходном порядке
+	impl.publisher = publisherFunc(func(messages []producer.Message) (int, error) {
+		published = append(published, messages...)
+		return len(messages), nil
+	})
+	sent, err = impl.RelayOutbox(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != pending-1 || len(store.state.outbox) != 0 {
+		t.Errorf("relay sent %d, %d left in outbox", sent, len(store.state.outbox))
+	}
+	if len(published) != pending {
+		t.Errorf("published %d messages, want %d", len(published), pending)
+	}
+}
+
+// memoryPaymentEvents разбирает события жизненного цикла платежей, ожидающие отправки в outbox
+func memoryPaymentEvents(t *testing.T, store *memoryStore) []*events.PaymentEvent {
+	t.Helper()
+
+	var paymentEvents []*events.PaymentEvent
+	for _, m := range store.state.outbox {
+		if m.message.Topic != "payment" {
+			continue
+		}
+		var envelope events.Envelope
+		if err := proto.Unmarshal(m.message.Value, &envelope); err != nil {
+			t.Fatal(err)
+		}
+		event := &events.PaymentEvent{}
+		if err := proto.Unmarshal(envelope.Payload, event); err != nil {
+			t.Fatal(err)
+		}
+		paymentEvents = append(paymentEvents, event)
+	}
+	return paymentEvents
+}
+
+func TestMemoryPaymentLifecycleEvents(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	firstMerchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+	secondMerchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	resp, err := impl.Authorize(context.Background(), &pb.AuthorizePayload{
+		CustomerWalletUserID: customerID,
+		Cents:                1000,
+		Currency:             "USD",
+		Splits: []*pb.MerchantSplit{
+			{MerchantWalletUserId: firstMerchantID, Cents: 700},
+			{MerchantWalletUserId: secondMerchantID, Cents: 300},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = impl.Void(context.Background(), &pb.VoidPayload{Pid: resp.Pid}); err != nil {
+		t.Fatal(err)
+	}
+
+	paymentEvents := memoryPaymentEvents(t, store)
+	if len(paymentEvents) != 2 {
+		t.Fatalf("got %d payment events, want 2", len(paymentEvents))
+	}
+	want := []struct {
+		eventType events.PaymentEventType
+		status    string
+	}{
+		{events.PaymentEventType_PAYMENT_AUTHORIZED, "AUTHORIZED"},
+		{events.PaymentEventType_PAYMENT_VOIDED, "VOIDED"},
+	}
+	for i, event := range paymentEvents {
+		if event.Type != want[i].eventType || event.Status != want[i].status {
+			t.Errorf("event %d: type %v status %s, want %v %s", i, event.Type, event.Status, want[i].eventType, want[i].status)
+		}
+		if event.Pid != resp.Pid || event.Amount != 1000 || event.Currency != "USD" {
+			t.Errorf("event %d: pid %s amount %d %s", i, event.Pid, event.Amount, event.Currency)
+		}
+		if event.Customer.GetUserId() != customerID || event.Customer.GetWalletId() == 0 {
+			t.Errorf("event %d: customer %v", i, event.Customer)
+		}
+		if len(event.Merchants) != 2 || event.Merchants[0].Merchant.GetUserId() != firstMerchantID || event.Merchants[1].Amount != 300 {
+			t.Errorf("event %d: merchants %v", i, event.Merchants)
+		}
+	}
+}
+
+// expireMemoryPayment переносит срок действия авторизации pid в прошлое
+func expireMemoryPayment(store *memoryStore, pid string) {
+	p := store.state.payments[pid]
+	p.expiresAt = time.Now().Add(-time.Second)
+	store.state.payments[pid] = p
+}
+
+func TestMemoryExpirePartiallyCaptured(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	resp, err := impl.Authorize(context.Background(), &pb.AuthorizePayload{
+		CustomerWalletUserID: customerID,
+		MerchantWalletUserID: merchantID,
+		Cents:                1000,
+		Currency:             "USD",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = impl.Capture(context.Background(), &pb.CapturePayload{Pid: resp.Pid, Cents: 400}); err != nil {
+		t.Fatal(err)
+	}
+
+	expireMemoryPayment(store, resp.Pid)
+	expired, err := impl.ExpireAuthorizations(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expired != 1 {
+		t.Errorf("expired = %d, want 1", expired)
+	}
+
+	// Неподтвержденный остаток вернулся покупателю, подтвержденная часть осталась у продавца
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 9600 {
+		t.Errorf("customer DEFAULT = %d, want 9600", got)
+	}
+	if got := memoryBalance(t, impl, customerID, "PAYMENT"); got != 0 {
+		t.Errorf("customer PAYMENT = %d, want 0", got)
+	}
+	if got := memoryBalance(t, impl, merchantID, "INCOMING"); got != 400 {
+		t.Errorf("merchant INCOMING = %d, want 400", got)
+	}
+	if got := store.state.payments[resp.Pid].status; got != statusCaptured {
+		t.Errorf("status = %s, want %s", got, statusCaptured)
+	}
+
+	// Подтвержденную часть можно вернуть как после финального подтверждения
+	if _, err = impl.Refund(context.Background(), &pb.RefundPayload{Pid: resp.Pid, Cents: 400}); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.state.payments[resp.Pid].status; got != statusRefunded {
+		t.Errorf("status after refund = %s, want %s", got, statusRefunded)
+	}
+}
+
+func TestMemoryCaptureExpiredPartiallyCaptured(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	resp, err := impl.Authorize(context.Background(), &pb.AuthorizePayload{
+		CustomerWalletUserID: customerID,
+		MerchantWalletUserID: merchantID,
+		Cents:                1000,
+		Currency:             "USD",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = impl.Capture(context.Background(), &pb.CapturePayload{Pid: resp.Pid, Cents: 400}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Срок истек, но обработчик истекших авторизаций еще не снял удержание остатка
+	expireMemoryPayment(store, resp.Pid)
+	_, err = impl.Capture(context.Background(), &pb.CapturePayload{Pid: resp.Pid, Cents: 600})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+	if got := memoryBalance(t, impl, merchantID, "INCOMING"); got != 400 {
+		t.Errorf("merchant INCOMING = %d, want 400", got)
+	}
+	if got := store.state.payments[resp.Pid].status; got != statusPartiallyCaptured {
+		t.Errorf("status = %s, want %s", got, statusPartiallyCaptured)
+	}
+}
+
+func TestMemoryExpireFxRemainderRoundedToZero(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	ctx := context.Background()
+
+	// Обменный пункт продает доллары за иены по курсу 1 JPY = 1 USD
+	tx, err := store.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	desk, err := tx.fetchWallet(fxDeskUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range []string{"JPY", "USD"} {
+		if err = tx.createAccount(desk.ID, fxAccountType, code); err != nil {
+			t.Fatal(err)
+		}
+		a, err := tx.fetchAccount(desk.ID, fxAccountType, code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = tx.creditAccount(a.ID, 100000); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	_, err = impl.SetFxRates(ctx, &pb.SetFxRatesPayload{Rates: []*pb.FxRate{{BaseCurrency: "JPY", QuoteCurrency: "USD", Rate: "1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Покупатель платит 10.00 USD со счета в иенах: удерживается 10 JPY
+	customerID := uuid.NewString()
+	_, err = impl.CreateWallet(ctx, &pb.CreateWalletPayload{UserId: customerID, WalletType: "CUSTOMER", Currencies: []string{"JPY"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = impl.Deposit(ctx, &pb.DepositPayload{UserId: customerID, Cents: 100, Currency: "JPY"}); err != nil {
+		t.Fatal(err)
+	}
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	quote, err := impl.CreateFxQuote(ctx, &pb.FxQuotePayload{SellCurrency: "JPY", BuyCurrency: "USD", BuyCents: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := impl.Authorize(ctx, &pb.AuthorizePayload{
+		CustomerWalletUserID: customerID,
+		MerchantWalletUserID: merchantID,
+		Cents:                1000,
+		Currency:             "USD",
+		FxQuoteId:            quote.QuoteId,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// После подтверждения 9.99 USD неподтвержденный остаток в иенах округляется до нуля
+	if _, err = impl.Capture(ctx, &pb.CapturePayload{Pid: resp.Pid, Cents: 999}); err != nil {
+		t.Fatal(err)
+	}
+	transactions := len(store.state.transactions)
+
+	expireMemoryPayment(store, resp.Pid)
+	expired, err := impl.ExpireAuthorizations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expired != 1 {
+		t.Errorf("expired = %d, want 1", expired)
+	}
+	if got := store.state.payments[resp.Pid].status; got != statusCaptured {
+		t.Errorf("status = %s, want %s", got, statusCaptured)
+	}
+	if got := len(store.state.transactions); got != transactions {
+		t.Errorf("transactions = %d, want %d (no release transaction)", got, transactions)
+	}
+}
+
+func TestMemoryExpirySkipsFailedPayments(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	// Больше одной пачки, чтобы проход перечитывал выборку
+	var pids []string
+	for range expiryBatchSize + 2 {
+		resp, err := impl.Authorize(context.Background(), &pb.AuthorizePayload{
+			CustomerWalletUserID: customerID,
+			MerchantWalletUserID: merchantID,
+			Cents:                10,
+			Currency:             "USD",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expireMemoryPayment(store, resp.Pid)
+		pids = append(pids, resp.Pid)
+	}
+
+	// Платеж, истекший раньше всех, ссылается на несуществующий кошелек и не может истечь
+	broken := store.state.payments[pids[0]]
+	broken.customerWalletID = -1
+	broken.expiresAt = time.Now().Add(-time.Hour)
+	store.state.payments[broken.pid] = broken
+
+	expired, err := impl.ExpireAuthorizations(context.Background())
+	if err == nil {
+		t.Fatal("expected error for broken payment")
+	}
+	if expired != len(pids)-1 {
+		t.Errorf("expired = %d, want %d", expired, len(pids)-1)
+	}
+	if got := store.state.payments[broken.pid].status; got != statusAuthorized {
+		t.Errorf("broken payment status = %s, want %s", got, statusAuthorized)
+	}
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 10000-10 {
+		t.Errorf("customer DEFAULT = %d, want %d", got, 10000-10)
+	}
+}
+
+func TestMemoryPayoutsSkipFailedPayouts(t *testing.T) {
+	store := newMemoryStore()
+	// Выплаты больше 500 центов банк отклоняет
+	impl := NewMoneyMovementImplementation(store, time.Hour, time.Minute, 0, payout.NewFakeBank(0, 500), nil)
+	brokenID := createMemoryWallet(t, impl, store, "MERCHANT", 1000)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 1000)
+
+	broken, err := impl.RequestPayout(context.Background(), &pb.RequestPayoutPayload{MerchantUserId: brokenID, Cents: 1000, Currency: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent, err := impl.RequestPayout(context.Background(), &pb.RequestPayoutPayload{MerchantUserId: merchantID, Cents: 100, Currency: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Отклоненная выплата обрабатывается первой, а вернуть деньги некуда: счета INCOMING нет
+	p := store.state.payouts[broken.PayoutId]
+	delete(store.state.payouts, p.ID)
+	p.ID = "0-" + p.ID
+	store.state.payouts[p.ID] = p
+	for id, a := range store.state.accounts {
+		if a.walletID == p.merchantWalletID && a.accountType == "INCOMING" {
+			delete(store.state.accounts, id)
+		}
+	}
+
+	completed, err := impl.ProcessPayouts(context.Background())
+	if err == nil {
+		t.Fatal("expected error for broken payout")
+	}
+	if completed != 1 {
+		t.Errorf("completed = %d, want 1", completed)
+	}
+	if got := store.state.payouts[p.ID].status; got != payoutStatusPending {
+		t.Errorf("broken payout status = %s, want %s", got, payoutStatusPending)
+	}
+	if got := store.state.payouts[sent.PayoutId].status; got != payoutStatusSent {
+		t.Errorf("payout status = %s, want %s", got, payoutStatusSent)
+	}
+}
+
+func TestMemoryLedgerEntryCurrency(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	userID := uuid.NewString()
+	_, err := impl.CreateWallet(context.Background(), &pb.CreateWalletPayload{UserId: userID, WalletType: "CUSTOMER", Currencies: []string{"EUR"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = impl.Deposit(context.Background(), &pb.DepositPayload{UserId: userID, Cents: 500, Currency: "EUR"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Сумма в леджере сопровождается валютой
+	var entries []*events.LedgerEntry
+	for _, m := range store.state.outbox {
+		if m.message.Topic != "ledger" {
+			continue
+		}
+		var envelope events.Envelope
+		if err := proto.Unmarshal(m.message.Value, &envelope); err != nil {
+			t.Fatal(err)
+		}
+		if envelope.Version != producer.EnvelopeVersion {
+			t.Errorf("envelope version = %d, want %d", envelope.Version, producer.EnvelopeVersion)
+		}
+		entry := &events.LedgerEntry{}
+		if err := proto.Unmarshal(envelope.Payload, entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("deposit wrote %d ledger entries, want 1", len(entries))
+	}
+	if entries[0].Amount != 500 || entries[0].Currency != "EUR" {
+		t.Errorf("ledger entry = %d %q, want 500 \"EUR\"", entries[0].Amount, entries[0].Currency)
+	}
+}
+
+func TestMemoryVoidAfterCapture(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	ctx := context.Background()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	authorize := func() string {
+		resp, err := impl.Authorize(ctx, &pb.AuthorizePayload{
+			CustomerWalletUserID: customerID,
+			MerchantWalletUserID: merchantID,
+			Cents:                1000,
+			Currency:             "USD",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Pid
+	}
+
+	// Отмена авторизации возвращает удержание покупателю
+	voided := authorize()
+	if _, err := impl.Void(ctx, &pb.VoidPayload{Pid: voided}); err != nil {
+		t.Fatal(err)
+	}
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 10000 {
+		t.Errorf("customer DEFAULT after void = %d, want 10000", got)
+	}
+
+	// Подтвержденный платеж отменить нельзя: деньги остаются у продавца
+	captured := authorize()
+	if _, err := impl.Capture(ctx, &pb.CapturePayload{Pid: captured}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := impl.Void(ctx, &pb.VoidPayload{Pid: captured})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("void after capture: expected FailedPrecondition, got %v", err)
+	}
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 9000 {
+		t.Errorf("customer DEFAULT = %d, want 9000", got)
+	}
+	if got := memoryBalance(t, impl, merchantID, "INCOMING"); got != 1000 {
+		t.Errorf("merchant INCOMING = %d, want 1000", got)
+	}
+	if got := store.state.payments[captured].status; got != statusCaptured {
+		t.Errorf("status = %s, want %s", got, statusCaptured)
+	}
+}
+
+func TestMemoryPartialCaptureFinal(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	ctx := context.Background()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	resp, err := impl.Authorize(ctx, &pb.AuthorizePayload{
+		CustomerWalletUserID: customerID,
+		MerchantWalletUserID: merchantID,
+		Cents:                1000,
+		Currency:             "USD",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Частичное подтверждение оставляет удержание остатка
+	if _, err = impl.Capture(ctx, &pb.CapturePayload{Pid: resp.Pid, Cents: 300}); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.state.payments[resp.Pid].status; got != statusPartiallyCaptured {
+		t.Errorf("status = %s, want %s", got, statusPartiallyCaptured)
+	}
+	if got := memoryBalance(t, impl, customerID, "PAYMENT"); got != 700 {
+		t.Errorf("customer PAYMENT = %d, want 700", got)
+	}
+
+	// Финальное подтверждение возвращает неподтвержденный остаток покупателю
+	if _, err = impl.Capture(ctx, &pb.CapturePayload{Pid: resp.Pid, Cents: 200, FinalCapture: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.state.payments[resp.Pid].status; got != statusCaptured {
+		t.Errorf("status = %s, want %s", got, statusCaptured)
+	}
+	if got := store.state.payments[resp.Pid].capturedAmount; got != 500 {
+		t.Errorf("captured = %d, want 500", got)
+	}
+	if got := memoryBalance(t, impl, customerID, "PAYMENT"); got != 0 {
+		t.Errorf("customer PAYMENT = %d, want 0", got)
+	}
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 9500 {
+		t.Errorf("customer DEFAULT = %d, want 9500", got)
+	}
+	if got := memoryBalance(t, impl, merchantID, "INCOMING"); got != 500 {
+		t.Errorf("merchant INCOMING = %d, want 500", got)
+	}
+
+	// Снятый остаток подтвердить уже нельзя
+	_, err = impl.Capture(ctx, &pb.CapturePayload{Pid: resp.Pid, Cents: 100})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("capture after final capture: expected FailedPrecondition, got %v", err)
+	}
+}
+
+func TestMemoryIllegalTransitions(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	ctx := context.Background()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	authorize := func() string {
+		resp, err := impl.Authorize(ctx, &pb.AuthorizePayload{
+			CustomerWalletUserID: customerID,
+			MerchantWalletUserID: merchantID,
+			Cents:                1000,
+			Currency:             "USD",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Pid
+	}
+
+	authorized := authorize()
+	voided := authorize()
+	if _, err := impl.Void(ctx, &pb.VoidPayload{Pid: voided}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		pid  string
+		call func(pid string) error
+	}{
+		{
+			name: "refund authorized",
+			pid:  authorized,
+			call: func(pid string) error {
+				_, err := impl.Refund(ctx, &pb.RefundPayload{Pid: pid, Cents: 100})
+				return err
+			},
+		},
+		{
+			name: "capture voided",
+			pid:  voided,
+			call: func(pid string) error {
+				_, err := impl.Capture(ctx, &pb.CapturePayload{Pid: pid})
+				return err
+			},
+		},
+		{
+			name: "void voided",
+			pid:  voided,
+			call: func(pid string) error {
+				_, err := impl.Void(ctx, &pb.VoidPayload{Pid: pid})
+				return err
+			},
+		},
+		{
+			name: "refund voided",
+			pid:  voided,
+			call: func(pid string) error {
+				_, err := impl.Refund(ctx, &pb.RefundPayload{Pid: pid, Cents: 100})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := store.state.payments[tt.pid].status
+			if err := tt.call(tt.pid); status.Code(err) != codes.FailedPrecondition {
+				t.Fatalf("expected FailedPrecondition, got %v", err)
+			}
+			if got := store.state.payments[tt.pid].status; got != before {
+				t.Errorf("status = %s, want unchanged %s", got, before)
+			}
+		})
+	}
+
+	// Отклоненные операции не двигают деньги: удержан только неотмененный платеж
+	if got := memoryBalance(t, impl, customerID, "PAYMENT"); got != 1000 {
+		t.Errorf("customer PAYMENT = %d, want 1000", got)
+	}
+	p, err := impl.GetPayment(ctx, &pb.GetPaymentPayload{Pid: voided})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != string(statusVoided) {
+		t.Errorf("GetPayment status = %s, want %s", p.Status, statusVoided)
+	}
+}
+
+func TestMemoryCurrencyMismatch(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	ctx := context.Background()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 0)
+
+	// Покупатель со счетами в долларах и евро, продавец принимает только доллары
+	euroCustomerID := uuid.NewString()
+	_, err := impl.CreateWallet(ctx, &pb.CreateWalletPayload{UserId: euroCustomerID, WalletType: "CUSTOMER", Currencies: []string{"USD", "EUR"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = impl.Deposit(ctx, &pb.DepositPayload{UserId: euroCustomerID, Cents: 5000, Currency: "EUR"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		customerID string
+		currency   string
+		want       codes.Code
+	}{
+		{name: "unknown currency", customerID: customerID, currency: "XXX", want: codes.InvalidArgument},
+		{name: "merchant does not accept", customerID: euroCustomerID, currency: "EUR", want: codes.FailedPrecondition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := impl.Authorize(ctx, &pb.AuthorizePayload{
+				CustomerWalletUserID: tt.customerID,
+				MerchantWalletUserID: merchantID,
+				Cents:                1000,
+				Currency:             tt.currency,
+			})
+			if status.Code(err) != tt.want {
+				t.Fatalf("expected %s, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// transfer не смешивает валюты, даже если счета переданы напрямую
+	tx, err := store.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tx.fetchWallet(euroCustomerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	euroAccount, err := tx.fetchAccount(w.ID, "DEFAULT", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dollarAccount, err := tx.fetchAccount(w.ID, "PAYMENT", "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = transfer(tx, euroAccount, dollarAccount, 100); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("transfer EUR -> USD: expected InvalidArgument, got %v", err)
+	}
+	if err = tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 10000 {
+		t.Errorf("customer DEFAULT = %d, want 10000", got)
+	}
+	if got := memoryBalance(t, impl, merchantID, "INCOMING"); got != 0 {
+		t.Errorf("merchant INCOMING = %d, want 0", got)
+	}
+}
+
+func TestMemoryDepositReplay(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	ctx := context.Background()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 0)
+
+	payload := &pb.DepositPayload{UserId: customerID, Cents: 2500, Currency: "USD", IdempotencyKey: uuid.NewString()}
+	first, err := impl.Deposit(ctx, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transactions := len(store.state.transactions)
+	outbox := len(store.state.outbox)
+
+	// Повтор с тем же ключом возвращает исходное пополнение и не зачисляет деньги еще раз
+	replay, err := impl.Deposit(ctx, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.DepositId != first.DepositId {
+		t.Errorf("replayed deposit id = %s, want %s", replay.DepositId, first.DepositId)
+	}
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 2500 {
+		t.Errorf("customer DEFAULT = %d, want 2500", got)
+	}
+	if got := len(store.state.transactions); got != transactions {
+		t.Errorf("transactions = %d, want %d", got, transactions)
+	}
+	if got := len(store.state.outbox); got != outbox {
+		t.Errorf("outbox messages = %d, want %d", got, outbox)
+	}
+
+	// Тот же ключ с другой суммой - ошибка клиента, а не новое пополнение
+	_, err = impl.Deposit(ctx, &pb.DepositPayload{UserId: customerID, Cents: 5000, Currency: "USD", IdempotencyKey: payload.IdempotencyKey})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("different payload: expected InvalidArgument, got %v", err)
+	}
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 2500 {
+		t.Errorf("customer DEFAULT after mismatched replay = %d, want 2500", got)
+	}
+}
+
+func TestMemoryTransferCustomersOnly(t *testing.T) {
+	impl, store := newMemoryTestImplementation()
+	ctx := context.Background()
+	customerID := createMemoryWallet(t, impl, store, "CUSTOMER", 10000)
+	friendID := createMemoryWallet(t, impl, store, "CUSTOMER", 0)
+	merchantID := createMemoryWallet(t, impl, store, "MERCHANT", 1000)
+
+	// Перевод между покупателями идет с DEFAULT на DEFAULT
+	_, err := impl.Transfer(ctx, &pb.TransferPayload{SrcUserId: customerID, DstUserId: friendID, Cents: 300, Currency: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := memoryBalance(t, impl, friendID, "DEFAULT"); got != 300 {
+		t.Errorf("friend DEFAULT = %d, want 300", got)
+	}
+
+	tests := []struct {
+		name  string
+		src   string
+		dst   string
+		cents int64
+	}{
+		{name: "customer to merchant", src: customerID, dst: merchantID, cents: 100},
+		{name: "merchant to customer", src: merchantID, dst: customerID, cents: 100},
+		{name: "customer to system wallet", src: customerID, dst: clearingUserID, cents: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := impl.Transfer(ctx, &pb.TransferPayload{SrcUserId: tt.src, DstUserId: tt.dst, Cents: tt.cents, Currency: "USD"})
+			if status.Code(err) != codes.FailedPrecondition {
+				t.Fatalf("expected FailedPrecondition, got %v", err)
+			}
+		})
+	}
+
+	if got := memoryBalance(t, impl, customerID, "DEFAULT"); got != 9700 {
+		t.Errorf("customer DEFAULT = %d, want 9700", got)
+	}
+	if got := memoryBalance(t, impl, merchantID, "INCOMING"); got != 1000 {
+		t.Errorf("merchant INCOMING = %d, want 1000", got)
+	}
+}
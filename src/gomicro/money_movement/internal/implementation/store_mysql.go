@@ -0,0 +1,788 @@
+package mm
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"github.com/sunr3d/gomicro/internal/producer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	insertWalletQuery             = "INSERT INTO wallet (user_id, wallet_type) VALUES (?, ?)"
+	selectWalletQuery             = "SELECT id, user_id, wallet_type FROM wallet WHERE user_id=?"
+	selectWalletWithWalletIDQuery = "SELECT id, user_id, wallet_type FROM wallet WHERE id=?"
+	insertAccountQuery            = "INSERT INTO account (cents, account_type, wallet_id, currency) VALUES (0, ?, ?, ?)"
+	// Счета системных кошельков в новой валюте создаются при первом обращении к ним
+	insertSystemAccountQuery       = "INSERT IGNORE INTO account (cents, account_type, wallet_id, currency) VALUES (0, ?, ?, ?)"
+	selectAccountQuery             = "SELECT id, cents, account_type, wallet_id, currency FROM account WHERE wallet_id=? AND account_type=? AND currency=?"
+	selectWalletAccountsQuery      = "SELECT id, cents, account_type, wallet_id, currency FROM account WHERE wallet_id = ? ORDER BY currency, account_type"
+	selectAccountForUpdateQuery    = "SELECT id FROM account WHERE id=? FOR UPDATE"
+	debitAccountQuery              = "UPDATE account SET cents = cents - ? WHERE id = ? AND cents >= ?"
+	debitAccountWithOverdraftQuery = "UPDATE account SET cents = cents - ? WHERE id = ?"
+	creditAccountQuery             = "UPDATE account SET cents = cents + ? WHERE id = ?"
+)
+
+const (
+	insertTransactionQuery = "INSERT INTO transaction (pid, src_user_id, dst_user_id, src_wallet_id, dst_wallet_id, src_account_type, dst_account_type, final_dst_merchant_wallet_id, amount, currency, fx_quote_id, fx_rate, fx_mid_rate, fx_spread_bps, memo) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	selectTransactionQuery = "SELECT id, pid, src_user_id, dst_user_id, src_wallet_id, dst_wallet_id, src_account_type, dst_account_type, final_dst_merchant_wallet_id, amount, currency, fx_quote_id, fx_rate, created_at FROM transaction WHERE pid = ? ORDER BY id"
+)
+
+const (
+	insertPaymentQuery = "INSERT INTO payment (pid, customer_wallet_id, merchant_wallet_id, amount, currency, funding_amount, funding_currency, fx_quote_id, status, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NOW() + INTERVAL ? SECOND)"
+	selectPaymentQuery = "SELECT pid, customer_wallet_id, merchant_wallet_id, amount, captured_amount, refunded_amount, currency, funding_amount, funding_currency, fx_quote_id, status, created_at, updated_at, expires_at, expires_at <= NOW() FROM payment WHERE pid = ?"
+	// Блокировка строки платежа до конца SQL транзакции: параллельные операции над тем же pid
+	// ждут ее завершения и видят уже обновленное состояние
+	selectPaymentForUpdateQuery = selectPaymentQuery + " FOR UPDATE"
+	updatePaymentQuery          = "UPDATE payment SET captured_amount = ?, refunded_amount = ?, status = ? WHERE pid = ?"
+	// Истекшие авторизации обрабатываются пачками, чтобы не держать долгих SQL транзакций
+	selectExpiredPaymentsQuery = "SELECT pid FROM payment WHERE status IN (?, ?) AND expires_at <= NOW() ORDER BY expires_at LIMIT ?"
+	insertPaymentSplitQuery    = "INSERT INTO payment_split (pid, position, merchant_wallet_id, amount) VALUES (?, ?, ?, ?)"
+	selectPaymentSplitsQuery   = "SELECT pid, merchant_wallet_id, amount, captured_amount, refunded_amount FROM payment_split WHERE pid = ? ORDER BY position"
+	updatePaymentSplitQuery    = "UPDATE payment_split SET captured_amount = ?, refunded_amount = ? WHERE pid = ? AND merchant_wallet_id = ?"
+)
+
+const (
+	insertIdempotencyKeyQuery = "INSERT INTO idempotency_key (idempotency_key, operation, fingerprint) VALUES (?, ?, ?)"
+	selectIdempotencyKeyQuery = "SELECT fingerprint, response FROM idempotency_key WHERE idempotency_key = ? AND operation = ?"
+	updateIdempotencyKeyQuery = "UPDATE idempotency_key SET response = ? WHERE idempotency_key = ? AND operation = ?"
+	mysqlErrDuplicateEntry    = 1062 // Код ошибки MySQL при нарушении уникального ключа
+)
+
+const (
+	upsertFxRateQuery     = "INSERT INTO fx_rate (base_currency, quote_currency, rate) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE rate = VALUES(rate)"
+	selectFxRateQuery     = "SELECT rate FROM fx_rate WHERE base_currency = ? AND quote_currency = ?"
+	insertFxQuoteQuery    = "INSERT INTO fx_quote (id, sell_currency, buy_currency, sell_amount, buy_amount, rate, mid_rate, spread_bps, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW() + INTERVAL ? SECOND)"
+	selectFxQuoteQuery    = "SELECT id, sell_currency, buy_currency, sell_amount, buy_amount, rate, mid_rate, spread_bps, expires_at, expires_at <= NOW(), pid FROM fx_quote WHERE id = ?"
+	updateFxQuotePidQuery = "UPDATE fx_quote SET pid = ? WHERE id = ?"
+	// Блокировка котировки до конца SQL транзакции: одну котировку нельзя использовать дважды
+	selectFxQuoteForUpdateQuery = selectFxQuoteQuery + " FOR UPDATE"
+	// Тарифный план продавца в валюте платежа. Без плана комиссия не взимается
+	selectFeePlanQuery = "SELECT percent_bps, fixed_cents FROM merchant_fee_plan WHERE merchant_wallet_id = ? AND currency = ?"
+)
+
+const (
+	insertPayoutQuery = "INSERT INTO payout (id, merchant_wallet_id, amount, currency, status) VALUES (?, ?, ?, ?, ?)"
+	selectPayoutQuery = "SELECT id, merchant_wallet_id, amount, currency, status, provider_reference, failure_reason, created_at, updated_at FROM payout WHERE id = ?"
+	// Блокировка строки выплаты до конца SQL транзакции: выплата завершается ровно один раз
+	selectPayoutForUpdateQuery = selectPayoutQuery + " FOR UPDATE"
+	updatePayoutQuery          = "UPDATE payout SET status = ?, failure_reason = ? WHERE id = ?"
+	updatePayoutReferenceQuery = "UPDATE payout SET provider_reference = ? WHERE id = ? AND status = ?"
+	// Выплаты в обработке перебираются страницами по id
+	selectPendingPayoutsQuery = "SELECT p.id, w.user_id, p.amount, p.currency, p.provider_reference FROM payout p JOIN wallet w ON w.id = p.merchant_wallet_id WHERE p.status = ? AND p.id > ? ORDER BY p.id LIMIT ?"
+)
+
+const (
+	insertOutboxQuery        = "INSERT INTO outbox (topic, message_key, payload, content_type) VALUES (?, ?, ?, ?)"
+	selectPendingOutboxQuery = "SELECT id, topic, message_key, payload, content_type, attempts FROM outbox WHERE sent_at IS NULL ORDER BY id LIMIT ?"
+	updateOutboxSentQuery    = "UPDATE outbox SET sent_at = NOW() WHERE id = ?"
+	updateOutboxFailedQuery  = "UPDATE outbox SET attempts = attempts + 1, last_error = ? WHERE id = ?"
+	outboxLastErrorMaxLen    = 1024 // Размер колонки outbox.last_error
+)
+
+// mysqlStore - хранилище в MySQL. Единица работы - SQL транзакция,
+// блокировки lock* - SELECT ... FOR UPDATE
+type mysqlStore struct {
+	db *sql.DB
+}
+
+// NewMySQLStore создает хранилище поверх подключения к MySQL
+//
+// Параметры:
+//   - db: подключение к базе данных (parseTime=true)
+//
+// Возвращает:
+//   - хранилище
+func NewMySQLStore(db *sql.DB) Store {
+	return &mysqlStore{db: db}
+}
+
+func (this *mysqlStore) Begin() (UnitOfWork, error) {
+	tx, err := this.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &mysqlUnitOfWork{tx: tx}, nil
+}
+
+// mysqlUnitOfWork - единица работы поверх SQL транзакции
+type mysqlUnitOfWork struct {
+	tx *sql.Tx
+}
+
+func (this *mysqlUnitOfWork) Commit() error {
+	return this.tx.Commit()
+}
+
+func (this *mysqlUnitOfWork) Rollback() error {
+	return this.tx.Rollback()
+}
+
+func (this *mysqlUnitOfWork) fetchWallet(userID string) (wallet, error) {
+	return this.queryWallet(selectWalletQuery, userID)
+}
+
+func (this *mysqlUnitOfWork) fetchWalletWithWalletID(walletID int32) (wallet, error) {
+	return this.queryWallet(selectWalletWithWalletIDQuery, walletID)
+}
+
+func (this *mysqlUnitOfWork) queryWallet(query string, arg any) (wallet, error) {
+	var w wallet
+	stmt, err := this.tx.Prepare(query)
+	if err != nil {
+		return w, status.Error(codes.Internal, err.Error())
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRow(arg).Scan(&w.ID, &w.userID, &w.walletType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return w, status.Error(codes.NotFound, err.Error())
+		}
+		return w, status.Error(codes.Internal, err.Error())
+	}
+	return w, nil
+}
+
+func (this *mysqlUnitOfWork) createWallet(userID string, walletType string) (int32, error) {
+	res, err := this.tx.Exec(insertWalletQuery, userID, walletType)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return 0, status.Error(codes.AlreadyExists, "wallet already exists")
+		}
+		return 0, status.Error(codes.Internal, err.Error())
+	}
+	walletID, err := res.LastInsertId()
+	if err != nil {
+		return 0, status.Error(codes.Internal, err.Error())
+	}
+	return int32(walletID), nil
+}
+
+func (this *mysqlUnitOfWork) createAccount(walletID int32, accountType string, currencyCode string) error {
+	_, err := this.tx.Exec(insertAccountQuery, accountType, walletID, currencyCode)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (this *mysqlUnitOfWork) ensureAccount(walletID int32, accountType string, currencyCode string) error {
+	_, err := this.tx.Exec(insertSystemAccountQuery, accountType, walletID, currencyCode)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (this *mysqlUnitOfWork) fetchAccount(walletID int32, accountType string, currencyCode string) (account, error) {
+	var a account
+	stmt, err := this.tx.Prepare(selectAccountQuery)
+	if err != nil {
+		return a, status.Error(codes.Internal, err.Error())
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRow(walletID, accountType, currencyCode).Scan(&a.ID, &a.cents, &a.accountType, &a.walletID, &a.currency)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return a, status.Error(codes.NotFound, fmt.Sprintf("%s account in %s not found", accountType, currencyCode))
+		}
+		return a, status.Error(codes.Internal, err.Error())
+	}
+	return a, nil
+}
+
+func (this *mysqlUnitOfWork) fetchWalletAccounts(walletID int32) ([]account, error) {
+	rows, err := this.tx.Query(selectWalletAccountsQuery, walletID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer rows.Close()
+
+	var accounts []account
+	for rows.Next() {
+		var a account
+		if err = rows.Scan(&a.ID, &a.cents, &a.accountType, &a.walletID, &a.currency); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		accounts = append(accounts, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return accounts, nil
+}
+
+// lockAccounts блокирует строки счетов (SELECT ... FOR UPDATE) до конца SQL транзакции.
+//
+// Счета всегда блокируются в порядке возрастания айди, поэтому транзакции,
+// затрагивающие одни и те же счета, ждут друг друга, а не взаимоблокируются.
+// Повторная блокировка уже заблокированного этой транзакцией счета ничего не делает.
+func (this *mysqlUnitOfWork) lockAccounts(accounts ...account) error {
+	ids := make([]int32, 0, len(accounts))
+	for _, a := range accounts {
+		ids = append(ids, a.ID)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	stmt, err := this.tx.Prepare(selectAccountForUpdateQuery)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer stmt.Close()
+
+	for _, id := range ids {
+		var lockedID int32
+		err = stmt.QueryRow(id).Scan(&lockedID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return status.Error(codes.NotFound, err.Error())
+			}
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+	return nil
+}
+
+// debitAccount списывает amount со счета относительным UPDATE. Без allowOverdraft
+// списание выполняется только при достаточном остатке (cents >= amount)
+func (this *mysqlUnitOfWork) debitAccount(accountID int32, amount int64, allowOverdraft bool) error {
+	var (
+		res sql.Result
+		err error
+	)
+	if allowOverdraft {
+		res, err = this.tx.Exec(debitAccountWithOverdraftQuery, amount, accountID)
+	} else {
+		res, err = this.tx.Exec(debitAccountQuery, amount, accountID, amount)
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if affected != 1 {
+		return status.Error(codes.Aborted, "not enough money")
+	}
+	return nil
+}
+
+func (this *mysqlUnitOfWork) creditAccount(accountID int32, amount int64) error {
+	_, err := this.tx.Exec(creditAccountQuery, amount, accountID)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (this *mysqlUnitOfWork) insertTransaction(pid string, srcAccount account, dstAccount account, srcWallet wallet, dstWallet wallet, finalDstWallet wallet, amount int64, quote *fxQuote, memo sql.NullString) error {
+	// Поля конвертации заполняются только для транзакций по котировке
+	var (
+		fxQuoteID   sql.NullString
+		fxRate      sql.NullString
+		fxMidRate   sql.NullString
+		fxSpreadBps sql.NullInt32
+	)
+	if quote != nil {
+		fxQuoteID = sql.NullString{String: quote.ID, Valid: true}
+		fxRate = sql.NullString{String: quote.rate, Valid: true}
+		fxMidRate = sql.NullString{String: quote.midRate, Valid: true}
+		fxSpreadBps = sql.NullInt32{Int32: quote.spreadBps, Valid: true}
+	}
+
+	// SQL запрос
+	stmt, err := this.tx.Prepare(insertTransactionQuery)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(
+		pid,                    // Уникальный айди транзакции
+		srcWallet.userID,       // Айди кошелька отправителя
+		dstWallet.userID,       // Айди кошелька получателя
+		srcWallet.ID,           // Айди счета отправления
+		dstWallet.ID,           // Айди счета получения
+		srcAccount.accountType, // Тип счета отправления
+		dstAccount.accountType, // Тип счета получения
+		finalDstWallet.ID,      // Айди кошелька продавца
+		amount,                 // Сумма в минорных единицах валюты
+		srcAccount.currency,    // Валюта перевода
+		fxQuoteID,              // Котировка конвертации
+		fxRate,                 // Курс со спредом
+		fxMidRate,              // Рыночный курс
+		fxSpreadBps,            // Спред в базисных пунктах
+		memo)                   // Комментарий к переводу
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
+}
+
+func (this *mysqlUnitOfWork) fetchTransactions(pid string) ([]transaction, error) {
+	stmt, err := this.tx.Prepare(selectTransactionQuery)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(pid)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer rows.Close()
+
+	var transactions []transaction
+	for rows.Next() {
+		var t transaction
+		err = rows.Scan(
+			&t.ID,
+			&t.pid,
+			&t.srcUserID,
+			&t.dstUserID,
+			&t.srcAccountWalletID,
+			&t.dstAccountWalletID,
+			&t.srcAccountType,
+			&t.dstAccountType,
+			&t.finalDstMerchantWalletID,
+			&t.amount,
+			&t.currency,
+			&t.fxQuoteID,
+			&t.fxRate,
+			&t.createdAt)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		transactions = append(transactions, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if len(transactions) == 0 {
+		return nil, status.Error(codes.NotFound, "payment not found")
+	}
+
+	return transactions, nil
+}
+
+// createPayment создает платеж в состоянии AUTHORIZED.
+// Срок действия считается по часам БД
+func (this *mysqlUnitOfWork) createPayment(p payment, ttl time.Duration) error {
+	stmt, err := this.tx.Prepare(insertPaymentQuery)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(
+		p.pid,
+		p.customerWalletID,
+		p.merchantWalletID,
+		p.amount,
+		p.currency,
+		p.fundingAmount,
+		p.fundingCurrency,
+		p.fxQuoteID,
+		statusAuthorized,
+		int64(ttl.Seconds()))
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
+}
+
+func (this *mysqlUnitOfWork) fetchPayment(pid string) (payment, error) {
+	return this.queryPayment(selectPaymentQuery, pid)
+}
+
+func (this *mysqlUnitOfWork) lockPayment(pid string) (payment, error) {
+	return this.queryPayment(selectPaymentForUpdateQuery, pid)
+}
+
+func (this *mysqlUnitOfWork) queryPayment(query string, pid string) (payment, error) {
+	var p payment
+
+	stmt, err := this.tx.Prepare(query)
+	if err != nil {
+		return p, status.Error(codes.Internal, err.Error())
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRow(pid).Scan(
+		&p.pid,
+		&p.customerWalletID,
+		&p.merchantWalletID,
+		&p.amount,
+		&p.capturedAmount,
+		&p.refundedAmount,
+		&p.currency,
+		&p.fundingAmount,
+		&p.fundingCurrency,
+		&p.fxQuoteID,
+		&p.status,
+		&p.createdAt,
+		&p.updatedAt,
+		&p.expiresAt,
+		&p.expired)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return p, status.Error(codes.NotFound, "payment not found")
+		}
+		return p, status.Error(codes.Internal, err.Error())
+	}
+
+	return p, nil
+}
+
+func (this *mysqlUnitOfWork) updatePayment(p payment) error {
+	stmt, err := this.tx.Prepare(updatePaymentQuery)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(p.capturedAmount, p.refundedAmount, p.status, p.pid)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
+}
+
+func (this *mysqlUnitOfWork) fetchExpiredPayments(limit int) ([]string, error) {
+	rows, err := this.tx.Query(selectExpiredPaymentsQuery, statusAuthorized, statusPartiallyCaptured, limit)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer rows.Close()
+
+	var pids []string
+	for rows.Next() {
+		var pid string
+		if err = rows.Scan(&pid); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		pids = append(pids, pid)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return pids, nil
+}
+
+func (this *mysqlUnitOfWork) createPaymentSplits(pid string, splits []paymentSplit) error {
+	stmt, err := this.tx.Prepare(insertPaymentSplitQuery)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer stmt.Close()
+
+	for i, s := range splits {
+		_, err = stmt.Exec(pid, i, s.merchantWalletID, s.amount)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+	return nil
+}
+
+func (this *mysqlUnitOfWork) fetchPaymentSplits(pid string) ([]paymentSplit, error) {
+	rows, err := this.tx.Query(selectPaymentSplitsQuery, pid)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer rows.Close()
+
+	var splits []paymentSplit
+	for rows.Next() {
+		var s paymentSplit
+		err = rows.Scan(&s.pid, &s.merchantWalletID, &s.amount, &s.capturedAmount, &s.refundedAmount)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		splits = append(splits, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if len(splits) == 0 {
+		return nil, status.Error(codes.Internal, fmt.Sprintf("payment %s has no merchant splits", pid))
+	}
+	return splits, nil
+}
+
+func (this *mysqlUnitOfWork) updatePaymentSplit(s paymentSplit) error {
+	_, err := this.tx.Exec(updatePaymentSplitQuery, s.capturedAmount, s.refundedAmount, s.pid, s.merchantWalletID)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// insertIdempotencyKey сохраняет новый ключ. Параллельная транзакция с тем же ключом
+// ждет завершения этой на уникальном индексе
+func (this *mysqlUnitOfWork) insertIdempotencyKey(operation string, key string, fingerprint string) (bool, error) {
+	_, err := this.tx.Exec(insertIdempotencyKeyQuery, key, operation, fingerprint)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return false, nil
+		}
+		return false, status.Error(codes.Internal, err.Error())
+	}
+	return true, nil
+}
+
+func (this *mysqlUnitOfWork) fetchIdempotencyKey(operation string, key string) (string, sql.Null[[]byte], error) {
+	var (
+		fingerprint string
+		response    sql.Null[[]byte] // NULL, пока запрос не завершен
+	)
+	err := this.tx.QueryRow(selectIdempotencyKeyQuery, key, operation).Scan(&fingerprint, &response)
+	if err != nil {
+		return "", response, status.Error(codes.Internal, err.Error())
+	}
+	return fingerprint, response, nil
+}
+
+func (this *mysqlUnitOfWork) updateIdempotencyResponse(operation string, key string, response []byte) error {
+	_, err := this.tx.Exec(updateIdempotencyKeyQuery, response, key, operation)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (this *mysqlUnitOfWork) upsertFxRate(baseCurrency string, quoteCurrency string, rate string) error {
+	_, err := this.tx.Exec(upsertFxRateQuery, baseCurrency, quoteCurrency, rate)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (this *mysqlUnitOfWork) fetchFxRateValue(baseCurrency string, quoteCurrency string) (string, error) {
+	var value string
+	err := this.tx.QueryRow(selectFxRateQuery, baseCurrency, quoteCurrency).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", status.Error(codes.NotFound, fmt.Sprintf("no fx rate for %s/%s", baseCurrency, quoteCurrency))
+		}
+		return "", status.Error(codes.Internal, err.Error())
+	}
+	return value, nil
+}
+
+// createFxQuote сохраняет котировку. Срок действия считается по часам БД
+func (this *mysqlUnitOfWork) createFxQuote(q fxQuote, ttl time.Duration) error {
+	_, err := this.tx.Exec(insertFxQuoteQuery,
+		q.ID,
+		q.sellCurrency,
+		q.buyCurrency,
+		q.sellAmount,
+		q.buyAmount,
+		q.rate,
+		q.midRate,
+		q.spreadBps,
+		int64(ttl.Seconds()))
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (this *mysqlUnitOfWork) fetchFxQuote(id string) (fxQuote, error) {
+	return this.queryFxQuote(selectFxQuoteQuery, id)
+}
+
+func (this *mysqlUnitOfWork) lockFxQuote(id string) (fxQuote, error) {
+	return this.queryFxQuote(selectFxQuoteForUpdateQuery, id)
+}
+
+func (this *mysqlUnitOfWork) queryFxQuote(query string, id string) (fxQuote, error) {
+	var q fxQuote
+	err := this.tx.QueryRow(query, id).Scan(
+		&q.ID,
+		&q.sellCurrency,
+		&q.buyCurrency,
+		&q.sellAmount,
+		&q.buyAmount,
+		&q.rate,
+		&q.midRate,
+		&q.spreadBps,
+		&q.expiresAt,
+		&q.expired,
+		&q.pid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return q, status.Error(codes.NotFound, "fx quote not found")
+		}
+		return q, status.Error(codes.Internal, err.Error())
+	}
+	return q, nil
+}
+
+func (this *mysqlUnitOfWork) setFxQuotePid(id string, pid string) error {
+	_, err := this.tx.Exec(updateFxQuotePidQuery, pid, id)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// fetchFeePlan возвращает тарифный план продавца или нулевой план, если он не настроен
+func (this *mysqlUnitOfWork) fetchFeePlan(merchantWalletID int32, currencyCode string) (feePlan, error) {
+	var plan feePlan
+	err := this.tx.QueryRow(selectFeePlanQuery, merchantWalletID, currencyCode).Scan(&plan.percentBps, &plan.fixedCents)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return feePlan{}, nil
+		}
+		return feePlan{}, status.Error(codes.Internal, err.Error())
+	}
+	return plan, nil
+}
+
+func (this *mysqlUnitOfWork) createPayout(p payoutRecord) error {
+	_, err := this.tx.Exec(insertPayoutQuery, p.ID, p.merchantWalletID, p.amount, p.currency, p.status)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (this *mysqlUnitOfWork) fetchPayout(payoutID string) (payoutRecord, error) {
+	return this.queryPayout(selectPayoutQuery, payoutID)
+}
+
+func (this *mysqlUnitOfWork) lockPayout(payoutID string) (payoutRecord, error) {
+	return this.queryPayout(selectPayoutForUpdateQuery, payoutID)
+}
+
+func (this *mysqlUnitOfWork) queryPayout(query string, payoutID string) (payoutRecord, error) {
+	var p payoutRecord
+	err := this.tx.QueryRow(query, payoutID).Scan(
+		&p.ID,
+		&p.merchantWalletID,
+		&p.amount,
+		&p.currency,
+		&p.status,
+		&p.providerReference,
+		&p.failureReason,
+		&p.createdAt,
+		&p.updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return p, status.Error(codes.NotFound, "payout not found")
+		}
+		return p, status.Error(codes.Internal, err.Error())
+	}
+	return p, nil
+}
+
+func (this *mysqlUnitOfWork) updatePayout(p payoutRecord) error {
+	_, err := this.tx.Exec(updatePayoutQuery, p.status, p.failureReason, p.ID)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (this *mysqlUnitOfWork) setPayoutReference(payoutID string, reference string) error {
+	_, err := this.tx.Exec(updatePayoutReferenceQuery, reference, payoutID, payoutStatusPending)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (this *mysqlUnitOfWork) fetchPendingPayouts(afterID string, limit int) ([]pendingPayout, error) {
+	rows, err := this.tx.Query(selectPendingPayoutsQuery, payoutStatusPending, afterID, limit)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer rows.Close()
+
+	var pending []pendingPayout
+	for rows.Next() {
+		var p pendingPayout
+		err = rows.Scan(&p.payout.ID, &p.payout.MerchantUserID, &p.payout.Amount, &p.payout.Currency, &p.reference)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		pending = append(pending, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return pending, nil
+}
+
+func (this *mysqlUnitOfWork) insertOutboxMessages(messages []producer.Message) error {
+	for _, m := range messages {
+		_, err := this.tx.Exec(insertOutboxQuery, m.Topic, m.Key, m.Value, m.ContentType)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+	return nil
+}
+
+func (this *mysqlUnitOfWork) fetchPendingOutboxMessages(limit int) ([]outboxMessage, error) {
+	rows, err := this.tx.Query(selectPendingOutboxQuery, limit)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer rows.Close()
+
+	var messages []outboxMessage
+	for rows.Next() {
+		var m outboxMessage
+		err = rows.Scan(&m.ID, &m.message.Topic, &m.message.Key, &m.message.Value, &m.message.ContentType, &m.attempts)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		messages = append(messages, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return messages, nil
+}
+
+func (this *mysqlUnitOfWork) markOutboxMessageSent(id int64) error {
+	_, err := this.tx.Exec(updateOutboxSentQuery, id)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (this *mysqlUnitOfWork) markOutboxMessageFailed(id int64, reason string) error {
+	// Текст ошибки обрезается по размеру колонки, не разрывая символы UTF-8
+	if len(reason) > outboxLastErrorMaxLen {
+		reason = strings.ToValidUTF8(reason[:outboxLastErrorMaxLen], "")
+	}
+	_, err := this.tx.Exec(updateOutboxFailedQuery, reason, id)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// isDuplicateEntry сообщает, что запрос нарушил уникальный ключ таблицы
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
@@ -0,0 +1,179 @@
+package mm
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sunr3d/gomicro/internal/currency"
+	"github.com/sunr3d/gomicro/internal/producer"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"unicode/utf8"
+)
+
+const maxMemoLength = 255 // Длина колонки transaction.memo в символах
+
+// Transfer переводит деньги между базовыми счетами двух покупателей за один шаг
+//
+// В отличие от платежа продавцу перевод не проходит через авторизацию и подтверждение:
+// средства сразу переходят с DEFAULT счета отправителя на DEFAULT счет получателя.
+// Проверка того, что отправитель - владелец исходного кошелька, выполняется в gateway.
+//
+// Основные шаги:
+//  1. Проверка участников, валюты, суммы и комментария
+//  2. Начало SQL транзакции и проверка ключа идемпотентности
+//  3. Получение кошельков покупателей и их базовых счетов
+//  4. Перевод средств и создание транзакции
+//  5. Запись событий о переводе отправителю и получателю в outbox
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - transferPayload: отправитель, получатель, сумма, валюта и комментарий
+//
+// Возвращает:
+//   - идентификатор перевода
+//   - ошибку в случае неудачи
+func (this *Implementation) Transfer(ctx context.Context, transferPayload *pb.TransferPayload) (*pb.TransferResponse, error) {
+	// Проверка участников перевода
+	if transferPayload.GetSrcUserId() == "" || transferPayload.GetDstUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "src_user_id and dst_user_id are required")
+	}
+	if transferPayload.SrcUserId == transferPayload.DstUserId {
+		return nil, status.Error(codes.InvalidArgument, "cannot transfer to the same wallet")
+	}
+
+	// Проверка кода валюты, суммы и комментария
+	if err := currency.Validate(transferPayload.GetCurrency()); err != nil {
+		return nil, err
+	}
+	if transferPayload.GetCents() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be positive")
+	}
+	if utf8.RuneCountInString(transferPayload.GetMemo()) > maxMemoLength {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("memo must be at most %d characters", maxMemoLength))
+	}
+
+	// Начало транзакции (включаем изолированный запрос)
+	tx, err := this.store.Begin()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Повторный запрос с тем же ключом идемпотентности не переводит деньги еще раз
+	response := &pb.TransferResponse{}
+	replayed, err := claimIdempotencyKey(tx, operationTransfer, transferPayload.GetIdempotencyKey(), transferPayload, response)
+	if err != nil || replayed {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+
+	// Получение кошельков отправителя и получателя
+	srcWallet, err := tx.fetchWallet(transferPayload.SrcUserId)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+	dstWallet, err := tx.fetchWallet(transferPayload.DstUserId)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Переводы доступны только между кошельками покупателей
+	if srcWallet.walletType != "CUSTOMER" || dstWallet.walletType != "CUSTOMER" {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, status.Error(codes.FailedPrecondition, "transfers are available only between customer wallets")
+	}
+
+	// Получение базовых счетов отправителя и получателя в валюте перевода
+	srcAccount, err := tx.fetchAccount(srcWallet.ID, "DEFAULT", transferPayload.Currency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+	dstAccount, err := tx.fetchAccount(dstWallet.ID, "DEFAULT", transferPayload.Currency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("recipient does not accept %s", transferPayload.Currency))
+		}
+		return nil, err
+	}
+
+	// Перевод средств (счета блокируются в порядке айди, встречные переводы не взаимоблокируются)
+	err = transfer(tx, srcAccount, dstAccount, transferPayload.Cents)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Создание транзакции перевода
+	transferID := uuid.NewString()
+	err = createTransferTransaction(
+		tx,                    // БД
+		transferID,            // айди транзакции
+		srcAccount,            // счет отправления
+		dstAccount,            // счет получения
+		srcWallet,             // кошелек отправителя
+		dstWallet,             // кошелек получателя
+		transferPayload.Cents, // сумма
+		transferPayload.Memo)  // комментарий
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Сохраняем ответ для повторов запроса с тем же ключом идемпотентности
+	response.TransferId = transferID
+	err = saveIdempotencyResponse(tx, operationTransfer, transferPayload.GetIdempotencyKey(), response)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// События о переводе отправителю и получателю (outbox)
+	messages, err := producer.TransferMessages(ctx, transferID, srcWallet.userID, dstWallet.userID, transferPayload.Cents, transferPayload.Currency)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	err = tx.insertOutboxMessages(messages)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Конец транзакции, коммит изменений в БД
+	err = tx.Commit()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return response, nil
+}
@@ -1,5 +1,11 @@
 package mm
 
+import (
+	"database/sql"
+	"github.com/sunr3d/gomicro/internal/producer"
+	"time"
+)
+
 type wallet struct {
 	ID         int32
 	userID     string
@@ -11,6 +17,7 @@ type account struct {
 	cents       int64
 	accountType string
 	walletID    int32
+	currency    string // Код валюты ISO 4217
 }
 
 type transaction struct {
@@ -26,4 +33,67 @@ type transaction struct {
 	dstAccountType           string
 	finalDstMerchantWalletID int32
 	amount                   int64
+	currency                 string
+	fxQuoteID                sql.NullString // Котировка, по которой проведена конвертация
+	fxRate                   sql.NullString
+	createdAt                time.Time
+}
+
+type payment struct {
+	pid              string
+	customerWalletID int32
+	merchantWalletID int32
+	amount           int64 // Авторизованная сумма
+	capturedAmount   int64 // Подтвержденная сумма
+	refundedAmount   int64 // Возвращенная покупателю сумма
+	currency         string
+	fundingAmount    int64          // Удержанная с покупателя сумма в валюте его счета
+	fundingCurrency  string         // Валюта счета покупателя (отличается от currency при конвертации)
+	fxQuoteID        sql.NullString // Котировка конвертации
+	status           paymentStatus
+	createdAt        time.Time
+	updatedAt        time.Time
+	expiresAt        time.Time // Срок действия авторизации
+	expired          bool      // Срок действия авторизации истек (по часам БД)
+}
+
+// paymentSplit - доля продавца в платеже. У обычного платежа одна доля на всю сумму
+type paymentSplit struct {
+	pid              string
+	merchantWalletID int32
+	amount           int64 // Доля продавца в авторизованной сумме
+	capturedAmount   int64 // Подтвержденная часть доли
+	refundedAmount   int64 // Возвращенная покупателю часть доли
+}
+
+type fxQuote struct {
+	ID           string
+	sellCurrency string // Валюта, которую продает покупатель
+	buyCurrency  string // Валюта платежа
+	sellAmount   int64
+	buyAmount    int64
+	rate         string // Курс покупателя со спредом (единиц buyCurrency за единицу sellCurrency)
+	midRate      string // Рыночный курс
+	spreadBps    int32
+	expiresAt    time.Time
+	expired      bool
+	pid          sql.NullString // Платеж, использовавший котировку
+}
+
+type payoutRecord struct {
+	ID                string
+	merchantWalletID  int32
+	amount            int64
+	currency          string
+	status            string         // PENDING/SENT/FAILED
+	providerReference sql.NullString // Идентификатор выплаты у провайдера
+	failureReason     sql.NullString
+	createdAt         time.Time
+	updatedAt         time.Time
+}
+
+type outboxMessage struct {
+	ID       int64
+	message  producer.Message
+	attempts int32 // Неудачные попытки отправки
 }
@@ -0,0 +1,158 @@
+package mm
+
+import (
+	"context"
+	"github.com/sunr3d/gomicro/internal/currency"
+	pb "github.com/sunr3d/gomicro/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"slices"
+)
+
+const defaultWalletCurrency = "USD"
+
+// walletAccountTypes - набор счетов, который создается для кошелька каждого типа в каждой валюте.
+// Системные кошельки (SYSTEM) через API не создаются
+var walletAccountTypes = map[string][]string{
+	"CUSTOMER": {"DEFAULT", "PAYMENT"},
+	"MERCHANT": {"INCOMING", "PAYOUT_PENDING"},
+}
+
+// CreateWallet создает кошелек пользователя вместе с набором счетов для его типа
+//
+// Основные шаги:
+//  1. Проверка типа кошелька и валют
+//  2. Начало SQL транзакции
+//  3. Создание кошелька (повторное создание для того же пользователя - AlreadyExists)
+//  4. Создание счетов с нулевым балансом в каждой валюте
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - createWalletPayload: пользователь, тип кошелька и валюты счетов
+//
+// Возвращает:
+//   - созданный кошелек со счетами
+//   - ошибку в случае неудачи
+func (this *Implementation) CreateWallet(ctx context.Context, createWalletPayload *pb.CreateWalletPayload) (*pb.Wallet, error) {
+	// Проверка пользователя и типа кошелька
+	if createWalletPayload.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	accountTypes, ok := walletAccountTypes[createWalletPayload.GetWalletType()]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "wallet_type must be CUSTOMER or MERCHANT")
+	}
+
+	// Проверка валют (без повторов, по умолчанию - USD)
+	currencies := slices.Clone(createWalletPayload.GetCurrencies())
+	if len(currencies) == 0 {
+		currencies = []string{defaultWalletCurrency}
+	}
+	for _, c := range currencies {
+		if err := currency.Validate(c); err != nil {
+			return nil, err
+		}
+	}
+	slices.Sort(currencies)
+	currencies = slices.Compact(currencies)
+
+	// Начало транзакции: кошелек создается только вместе со всеми счетами
+	tx, err := this.store.Begin()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Создание кошелька
+	walletID, err := tx.createWallet(createWalletPayload.UserId, createWalletPayload.WalletType)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Создание счетов кошелька в каждой валюте
+	for _, c := range currencies {
+		for _, accountType := range accountTypes {
+			err = tx.createAccount(walletID, accountType, c)
+			if err != nil {
+				if rollbackErr := tx.Rollback(); rollbackErr != nil {
+					return nil, status.Error(codes.Internal, rollbackErr.Error())
+				}
+				return nil, err
+			}
+		}
+	}
+
+	// Получение созданного кошелька со счетами
+	w, err := tx.fetchWalletWithWalletID(walletID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+	accounts, err := tx.fetchWalletAccounts(w.ID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, status.Error(codes.Internal, rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	// Конец транзакции, коммит изменений в БД
+	err = tx.Commit()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return walletToProto(w, accounts), nil
+}
+
+// GetWallet возвращает кошелек пользователя и балансы всех его счетов
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - getWalletPayload: идентификатор пользователя
+//
+// Возвращает:
+//   - кошелек со счетами
+//   - ошибку в случае неудачи
+func (this *Implementation) GetWallet(ctx context.Context, getWalletPayload *pb.GetWalletPayload) (*pb.Wallet, error) {
+	// Начало транзакции (кошелек и счета читаются по одному снимку данных)
+	tx, err := this.store.Begin()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer func() {
+		// Транзакция только читает данные, поэтому всегда откатывается
+		_ = tx.Rollback()
+	}()
+
+	w, err := tx.fetchWallet(getWalletPayload.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := tx.fetchWalletAccounts(w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return walletToProto(w, accounts), nil
+}
+
+func walletToProto(w wallet, accounts []account) *pb.Wallet {
+	result := &pb.Wallet{
+		UserId:     w.userID,
+		WalletType: w.walletType,
+		Accounts:   make([]*pb.Account, 0, len(accounts)),
+	}
+	for _, a := range accounts {
+		result.Accounts = append(result.Accounts, &pb.Account{
+			AccountType: a.accountType,
+			Currency:    a.currency,
+			Cents:       a.cents,
+		})
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package payout
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FakeBank - локальная реализация Provider для разработки и тестов.
+//
+// Выплата считается отправленной через settleDelay после передачи в банк.
+// Выплаты больше maxAmount отклоняются, что позволяет проверить сценарий отказа.
+type FakeBank struct {
+	settleDelay time.Duration
+	maxAmount   int64 // 0 - без ограничения
+
+	mu      sync.Mutex
+	payouts map[string]fakePayout // Ключ - идентификатор выплаты у банка
+	byID    map[string]string     // Идентификатор выплаты в сервисе -> идентификатор у банка
+}
+
+type fakePayout struct {
+	payout      Payout
+	submittedAt time.Time
+}
+
+// NewFakeBank создает фейковый банк
+//
+// Параметры:
+//   - settleDelay: время от передачи выплаты до ее отправки
+//   - maxAmount: максимальная сумма выплаты (0 - без ограничения)
+func NewFakeBank(settleDelay time.Duration, maxAmount int64) *FakeBank {
+	return &FakeBank{
+		settleDelay: settleDelay,
+		maxAmount:   maxAmount,
+		payouts:     make(map[string]fakePayout),
+		byID:        make(map[string]string),
+	}
+}
+
+// Submit регистрирует выплату в банке. Повторная передача той же выплаты
+// возвращает идентификатор, выданный в первый раз
+func (this *FakeBank) Submit(ctx context.Context, p Payout) (string, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	if reference, ok := this.byID[p.ID]; ok {
+		return reference, nil
+	}
+
+	reference := fmt.Sprintf("fake-%d", len(this.payouts)+1)
+	this.payouts[reference] = fakePayout{payout: p, submittedAt: time.Now()}
+	this.byID[p.ID] = reference
+	return reference, nil
+}
+
+// Status возвращает состояние выплаты
+func (this *FakeBank) Status(ctx context.Context, reference string) (Result, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	fp, ok := this.payouts[reference]
+	if !ok {
+		return Result{}, fmt.Errorf("unknown payout reference %q", reference)
+	}
+
+	if this.maxAmount > 0 && fp.payout.Amount > this.maxAmount {
+		return Result{Status: StatusFailed, Reason: "amount exceeds bank limit"}, nil
+	}
+	if time.Since(fp.submittedAt) < this.settleDelay {
+		return Result{Status: StatusPending}, nil
+	}
+	return Result{Status: StatusSent}, nil
+}
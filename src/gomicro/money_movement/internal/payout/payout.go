@@ -0,0 +1,40 @@
+// Package payout описывает провайдеров выплат продавцам на внешние банковские счета
+package payout
+
+import "context"
+
+// Status - состояние выплаты у провайдера
+type Status string
+
+const (
+	StatusPending Status = "PENDING" // Выплата принята провайдером и еще обрабатывается
+	StatusSent    Status = "SENT"    // Деньги отправлены на счет продавца
+	StatusFailed  Status = "FAILED"  // Банк отклонил выплату
+)
+
+// Payout - выплата, передаваемая провайдеру
+type Payout struct {
+	ID             string // Идентификатор выплаты в сервисе (ключ идемпотентности у провайдера)
+	MerchantUserID string
+	Amount         int64 // Сумма в минорных единицах валюты
+	Currency       string
+}
+
+// Result - состояние выплаты у провайдера
+type Result struct {
+	Status Status
+	Reason string // Причина отказа для StatusFailed
+}
+
+// Provider отправляет выплаты во внешний банк.
+//
+// Ошибки методов считаются временными: выплата остается в обработке и будет
+// повторена. Окончательный отказ банка возвращается через Status как StatusFailed.
+type Provider interface {
+	// Submit передает выплату провайдеру и возвращает ее идентификатор у провайдера.
+	// Повторный вызов для той же payout.ID не должен создавать вторую выплату
+	Submit(ctx context.Context, p Payout) (string, error)
+
+	// Status возвращает текущее состояние выплаты по идентификатору провайдера
+	Status(ctx context.Context, reference string) (Result, error)
+}
@@ -0,0 +1,112 @@
+package producer
+
+import (
+	"context"
+	"github.com/sunr3d/gomicro/events"
+)
+
+// Party - участник платежа
+type Party struct {
+	UserID   string
+	WalletID int32
+}
+
+// MerchantShare - часть перехода платежа, приходящаяся на одного продавца
+type MerchantShare struct {
+	Merchant Party
+	Amount   int64         // Сумма в валюте платежа
+	Fee      *FeeBreakdown // Комиссия подтверждения или возвращенная при возврате часть комиссии
+}
+
+// PaymentEvent - данные перехода платежа в новое состояние
+type PaymentEvent struct {
+	Pid       string
+	Status    string // Состояние платежа после перехода
+	Currency  string
+	Customer  Party
+	Merchants []MerchantShare // Продавцы, которых касается переход
+}
+
+// PaymentAuthorizedMessages создает событие об авторизации платежа
+// (доли продавцов - авторизованные суммы)
+func PaymentAuthorizedMessages(ctx context.Context, e PaymentEvent) ([]Message, error) {
+	return paymentMessages(ctx, e, events.PaymentEventType_PAYMENT_AUTHORIZED, "")
+}
+
+// PaymentCapturedMessages создает событие о подтверждении платежа и записи для бухгалтерии:
+// по списанию с покупателя на каждого продавца с разбивкой суммы на комиссию и выручку
+func PaymentCapturedMessages(ctx context.Context, e PaymentEvent) ([]Message, error) {
+	return paymentMessages(ctx, e, events.PaymentEventType_PAYMENT_CAPTURED, "DEBIT")
+}
+
+// PaymentVoidedMessages создает событие об отмене авторизации
+// (доли продавцов - освобожденные суммы)
+func PaymentVoidedMessages(ctx context.Context, e PaymentEvent) ([]Message, error) {
+	return paymentMessages(ctx, e, events.PaymentEventType_PAYMENT_VOIDED, "")
+}
+
+// PaymentRefundedMessages создает событие о возврате средств покупателю
+// и запись для бухгалтерии о зачислении на счет покупателя
+func PaymentRefundedMessages(ctx context.Context, e PaymentEvent) ([]Message, error) {
+	return paymentMessages(ctx, e, events.PaymentEventType_PAYMENT_REFUNDED, "CREDIT")
+}
+
+// PaymentExpiredMessages создает событие об истечении авторизации и запись для бухгалтерии
+// о возврате неподтвержденного остатка на счет покупателя
+func PaymentExpiredMessages(ctx context.Context, e PaymentEvent) ([]Message, error) {
+	return paymentMessages(ctx, e, events.PaymentEventType_PAYMENT_EXPIRED, "EXPIRE")
+}
+
+// paymentMessages создает событие перехода платежа для топика payment и, если задана
+// operation, записи для бухгалтерского консюмера по каждому продавцу
+func paymentMessages(ctx context.Context, e PaymentEvent, eventType events.PaymentEventType, operation string) ([]Message, error) {
+	event := &events.PaymentEvent{
+		Pid:      e.Pid,
+		Type:     eventType,
+		Status:   e.Status,
+		Currency: e.Currency,
+		Customer: &events.Party{UserId: e.Customer.UserID, WalletId: e.Customer.WalletID},
+	}
+	for _, share := range e.Merchants {
+		event.Amount += share.Amount
+		event.Merchants = append(event.Merchants, &events.MerchantShare{
+			Merchant: &events.Party{UserId: share.Merchant.UserID, WalletId: share.Merchant.WalletID},
+			Amount:   share.Amount,
+			Fee:      share.Fee.toEvent(),
+		})
+	}
+
+	message, err := newMessage(ctx, paymentTopic, e.Pid, event)
+	if err != nil {
+		return nil, err
+	}
+	messages := []Message{message}
+
+	if operation == "" {
+		return messages, nil
+	}
+	for _, share := range e.Merchants {
+		_, entry := newMessages(e.Pid, e.Customer.UserID, share.Amount, e.Currency, operation)
+		entry.Fee = share.Fee.toEvent()
+		message, err = newMessage(ctx, ledgeTopic, e.Pid, entry)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// toEvent переводит разбивку в сообщение события (nil - без разбивки)
+func (this *FeeBreakdown) toEvent() *events.Fee {
+	if this == nil {
+		return nil
+	}
+	return &events.Fee{
+		MerchantUserId: this.MerchantUserID,
+		PercentBps:     this.PercentBps,
+		FixedCents:     this.FixedCents,
+		FeeCents:       this.FeeCents,
+		NetCents:       this.NetCents,
+	}
+}
@@ -2,101 +2,164 @@
 package producer
 
 import (
-	"encoding/json"
-	"github.com/IBM/sarama"
-	"log"
-	"os"
-	"sync"
+	"context"
+	"github.com/google/uuid"
+	"github.com/sunr3d/gomicro/events"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"time"
 )
 
 const (
-	emailTopic = "email"
-	ledgeTopic = "ledger"
+	emailTopic   = "email"
+	ledgeTopic   = "ledger"
+	paymentTopic = "payment" // События жизненного цикла платежей (events.PaymentEvent)
 )
 
-type EmailMsg struct {
-	OrderID string `json:"order_id"`
-	UserID  string `json:"user_id"`
+// Заголовок Кафки content-type отличает конверт от сообщений в JSON,
+// опубликованных до его появления
+const (
+	ContentTypeHeader   = "content-type"
+	ContentTypeJSON     = "application/json"
+	ContentTypeEnvelope = "application/x-protobuf; type=events.Envelope"
+)
+
+// EnvelopeVersion - текущая версия схемы payload в конверте.
+// Версия 2 добавила валюту в LedgerEntry; консюмеры обновляются раньше продюсера
+const EnvelopeVersion = 2
+
+// traceHeaders - заголовки gRPC запроса, которые переносятся в конверт события
+var traceHeaders = []string{"traceparent", "tracestate"}
+
+// FeeBreakdown - разбивка подтвержденной суммы между продавцом и платформой
+type FeeBreakdown struct {
+	MerchantUserID string
+	PercentBps     int32 // Процент тарифного плана в базисных пунктах
+	FixedCents     int64 // Фиксированная часть тарифного плана
+	FeeCents       int64 // Комиссия платформы
+	NetCents       int64 // Сумма, зачисленная продавцу
+}
+
+// Message - сообщение для Кафки, подготовленное к отправке
+type Message struct {
+	Topic       string
+	Key         string // Идентификатор операции (pid, пополнения, выплаты или перевода)
+	Value       []byte // Сериализованный events.Envelope
+	ContentType string // Значение заголовка content-type
 }
 
-type LedgerMsg struct {
-	OrderID   string `json:"order_id"`
-	UserID    string `json:"user_id"`
-	Amount    int64  `json:"amount"`
-	Operation string `json:"operation"`
-	Date      string `json:"date"`
+// DepositMessages создает события о пополнении счета покупателя
+// (зачисление на счет покупателя)
+func DepositMessages(ctx context.Context, depositID string, userID string, amount int64, currency string) ([]Message, error) {
+	notification, entry := newMessages(depositID, userID, amount, currency, "CREDIT")
+	return encode(ctx, notification, entry)
 }
 
-func SendCaptureMessage(pid string, userID string, amount int64) {
-	sarama.Logger = log.New(os.Stdout, "[sarama] ", log.LstdFlags)
-	config := sarama.NewConfig()
-	config.Producer.Return.Successes = true
-	// Создание синхронного продюсера (отправителя) Кафка (через библу Sarama)
-	producer, err := sarama.NewSyncProducer([]string{"my-cluster-kafka-bootstrap:9092"}, config)
+// PayoutRequestedMessages создает события о запросе выплаты продавцу
+// (средства зарезервированы на счете PAYOUT_PENDING)
+func PayoutRequestedMessages(ctx context.Context, payoutID string, userID string, amount int64, currency string) ([]Message, error) {
+	notification, entry := newMessages(payoutID, userID, amount, currency, "PAYOUT_REQUESTED")
+	return encode(ctx, notification, entry)
+}
+
+// PayoutSentMessages создает события об отправке выплаты в банк продавца
+// (списание со счета продавца)
+func PayoutSentMessages(ctx context.Context, payoutID string, userID string, amount int64, currency string) ([]Message, error) {
+	notification, entry := newMessages(payoutID, userID, amount, currency, "PAYOUT_SENT")
+	return encode(ctx, notification, entry)
+}
+
+// PayoutFailedMessages создает события об отказе банка в выплате
+// (средства возвращены на счет INCOMING продавца)
+func PayoutFailedMessages(ctx context.Context, payoutID string, userID string, amount int64, currency string) ([]Message, error) {
+	notification, entry := newMessages(payoutID, userID, amount, currency, "PAYOUT_FAILED")
+	return encode(ctx, notification, entry)
+}
+
+// TransferMessages создает события о переводе между пользователями
+// (списание у отправителя и зачисление получателю)
+func TransferMessages(ctx context.Context, transferID string, srcUserID string, dstUserID string, amount int64, currency string) ([]Message, error) {
+	notification, entry := newMessages(transferID, srcUserID, amount, currency, "TRANSFER_OUT")
+	out, err := encode(ctx, notification, entry)
 	if err != nil {
-		log.Println(err)
-		return
+		return nil, err
 	}
-	defer func() {
-		if err := producer.Close(); err != nil {
-			log.Println(err)
-		}
-	}()
+	notification, entry = newMessages(transferID, dstUserID, amount, currency, "TRANSFER_IN")
+	in, err := encode(ctx, notification, entry)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, in...), nil
+}
 
-	// Создание сообщений для разных консюмеров:
+// newMessages создает сообщения для е-мейл и бухгалтерского консюмеров.
+// amount - в минорных единицах валюты currency
+func newMessages(pid string, userID string, amount int64, currency string, operation string) (*events.EmailNotification, *events.LedgerEntry) {
 	// Сообщение для е-мейл консюмера,
-	emailMsg := EmailMsg{
-		OrderID: pid,
-		UserID:  userID,
+	notification := &events.EmailNotification{
+		OrderId: pid,
+		UserId:  userID,
 	}
 
 	// Сообщение для бухгалтерского консюмера
-	ledgerMsg := LedgerMsg{
-		OrderID:   pid,
-		UserID:    userID,
+	entry := &events.LedgerEntry{
+		OrderId:   pid,
+		UserId:    userID,
 		Amount:    amount,
-		Operation: "DEBIT",
+		Operation: operation,
 		Date:      time.Now().Format("2006-01-02"),
+		Currency:  currency,
 	}
-
-	// Асинхронная отправка сообщений в очередь Кафки с помощью горутин
-	wg := new(sync.WaitGroup)
-	wg.Add(2)
-	go sendMsg(producer, emailMsg, emailTopic, wg)
-	go sendMsg(producer, ledgerMsg, ledgeTopic, wg)
-	wg.Wait()
+	return notification, entry
 }
 
-func sendMsg[T EmailMsg | LedgerMsg](
-	producer sarama.SyncProducer,
-	msg T,
-	topic string,
-	wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	// Перевод переданного сообщения (msg) в JSON (stringMsg)
-	stringMsg, err := json.Marshal(msg)
+// encode упаковывает сообщения консюмеров в конверты для отправки в их топики
+func encode(ctx context.Context, notification *events.EmailNotification, entry *events.LedgerEntry) ([]Message, error) {
+	emailMessage, err := newMessage(ctx, emailTopic, notification.OrderId, notification)
+	if err != nil {
+		return nil, err
+	}
+	ledgerMessage, err := newMessage(ctx, ledgeTopic, entry.OrderId, entry)
 	if err != nil {
-		log.Println(err)
-		return
+		return nil, err
 	}
+	return []Message{emailMessage, ledgerMessage}, nil
+}
 
-	// Создание сообщения для Кафки
-	// message будет иметь тип структуры ProducerMessage из пакета sarama (тип для Кафки)
-	message := &sarama.ProducerMessage{
-		Topic: topic,                           // Топик определяет канал отправки
-		Value: sarama.StringEncoder(stringMsg), // Перевод сообщения из JSON в формат для Кафки
+// newMessage создает сообщение топика topic с payload в конверте
+func newMessage(ctx context.Context, topic string, key string, payload proto.Message) (Message, error) {
+	value, err := wrap(ctx, payload)
+	if err != nil {
+		return Message{}, err
 	}
+	return Message{Topic: topic, Key: key, Value: value, ContentType: ContentTypeEnvelope}, nil
+}
 
-	// Отправляем сообщение на Кафку
-	// partition вернет раздел топика в которое улетело сообщение
-	// offset вернет позицию сообщения в партиции
-	partition, offset, err := producer.SendMessage(message)
+// wrap сериализует payload в конверт с новым идентификатором события
+// и заголовками трассировки входящего gRPC запроса (если они есть)
+func wrap(ctx context.Context, payload proto.Message) ([]byte, error) {
+	value, err := proto.Marshal(payload)
 	if err != nil {
-		log.Println(err)
-		return
+		return nil, err
 	}
 
-	log.Printf("Message sent to partition %d at offset %d\n", partition, offset)
+	envelope := &events.Envelope{
+		EventId:    uuid.NewString(),
+		Type:       string(payload.ProtoReflect().Descriptor().FullName()),
+		Version:    EnvelopeVersion,
+		OccurredAt: timestamppb.Now(),
+		Payload:    value,
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, name := range traceHeaders {
+			if values := md.Get(name); len(values) > 0 {
+				if envelope.Trace == nil {
+					envelope.Trace = make(map[string]string, len(traceHeaders))
+				}
+				envelope.Trace[name] = values[0]
+			}
+		}
+	}
+	return proto.Marshal(envelope)
 }
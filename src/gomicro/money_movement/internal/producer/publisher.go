@@ -0,0 +1,162 @@
+package producer
+
+import (
+	"fmt"
+	"github.com/IBM/sarama"
+	"log"
+	"os"
+	"sync"
+)
+
+// Publisher отправляет подготовленные сообщения в Кафку
+type Publisher interface {
+	// Publish синхронно отправляет сообщения в заданном порядке и останавливается
+	// на первой ошибке. Возвращает количество отправленных сообщений (с начала списка)
+	Publish(messages []Message) (int, error)
+
+	// Close завершает отправку и освобождает соединения с брокерами
+	Close() error
+}
+
+// Config - настройки продюсера Кафки
+type Config struct {
+	Brokers      []string // Адреса брокеров (host:port)
+	RequiredAcks string   // Подтверждение записи: none, leader или all
+	Compression  string   // Сжатие: none, gzip, snappy, lz4 или zstd
+	Idempotent   bool     // Идемпотентный продюсер (требует RequiredAcks = all)
+}
+
+// KafkaPublisher - Publisher поверх одного синхронного продюсера Sarama.
+// Продюсер подключается к брокерам при первой отправке (и повторно после
+// неудачного подключения), поэтому сервис запускается и при недоступной Кафке,
+// а события копятся в outbox до ее появления
+type KafkaPublisher struct {
+	brokers  []string
+	config   *sarama.Config
+	mu       sync.Mutex
+	producer sarama.SyncProducer // nil, пока подключение не удалось
+}
+
+// NewKafkaPublisher проверяет настройки продюсера. К брокерам он не подключается:
+// подключение происходит при первой отправке
+//
+// Сообщения распределяются по партициям по хешу ключа (идентификатора операции),
+// поэтому события одного платежа попадают в одну партицию и читаются по порядку.
+//
+// Параметры:
+//   - config: настройки продюсера
+//
+// Возвращает:
+//   - продюсер, который нужно закрыть через Close
+//   - ошибку в случае неверных настроек
+func NewKafkaPublisher(config Config) (*KafkaPublisher, error) {
+	if len(config.Brokers) == 0 {
+		return nil, fmt.Errorf("no kafka brokers configured")
+	}
+
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Producer.Return.Successes = true
+	saramaConfig.Producer.Partitioner = sarama.NewHashPartitioner
+
+	switch config.RequiredAcks {
+	case "none":
+		saramaConfig.Producer.RequiredAcks = sarama.NoResponse
+	case "leader", "":
+		saramaConfig.Producer.RequiredAcks = sarama.WaitForLocal
+	case "all":
+		saramaConfig.Producer.RequiredAcks = sarama.WaitForAll
+	default:
+		return nil, fmt.Errorf("unknown kafka acks %q", config.RequiredAcks)
+	}
+
+	switch config.Compression {
+	case "none", "":
+		saramaConfig.Producer.Compression = sarama.CompressionNone
+	case "gzip":
+		saramaConfig.Producer.Compression = sarama.CompressionGZIP
+	case "snappy":
+		saramaConfig.Producer.Compression = sarama.CompressionSnappy
+	case "lz4":
+		saramaConfig.Producer.Compression = sarama.CompressionLZ4
+	case "zstd":
+		saramaConfig.Producer.Compression = sarama.CompressionZSTD
+	default:
+		return nil, fmt.Errorf("unknown kafka compression %q", config.Compression)
+	}
+
+	if config.Idempotent {
+		if saramaConfig.Producer.RequiredAcks != sarama.WaitForAll {
+			return nil, fmt.Errorf("idempotent kafka producer requires acks=all")
+		}
+		// Повторные отправки не создают дублей и не меняют порядок сообщений
+		// только при одном запросе в полете на брокера
+		saramaConfig.Producer.Idempotent = true
+		saramaConfig.Net.MaxOpenRequests = 1
+	}
+
+	if err := saramaConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	sarama.Logger = log.New(os.Stdout, "[sarama] ", log.LstdFlags)
+	return &KafkaPublisher{brokers: config.Brokers, config: saramaConfig}, nil
+}
+
+// connect возвращает продюсер, подключаясь к брокерам, если подключения еще нет.
+// Вызывается под this.mu
+func (this *KafkaPublisher) connect() (sarama.SyncProducer, error) {
+	if this.producer != nil {
+		return this.producer, nil
+	}
+
+	// Создание синхронного продюсера (отправителя) Кафка (через библу Sarama)
+	producer, err := sarama.NewSyncProducer(this.brokers, this.config)
+	if err != nil {
+		return nil, fmt.Errorf("connect to kafka: %w", err)
+	}
+	this.producer = producer
+	return producer, nil
+}
+
+func (this *KafkaPublisher) Publish(messages []Message) (int, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	producer, err := this.connect()
+	if err != nil {
+		return 0, err
+	}
+
+	for i, m := range messages {
+		// Создание сообщения для Кафки
+		// Ключ сообщения - идентификатор операции, он определяет партицию
+		message := &sarama.ProducerMessage{
+			Topic: m.Topic,
+			Key:   sarama.StringEncoder(m.Key),
+			Value: sarama.ByteEncoder(m.Value),
+		}
+		if m.ContentType != "" {
+			message.Headers = []sarama.RecordHeader{{Key: []byte(ContentTypeHeader), Value: []byte(m.ContentType)}}
+		}
+
+		// Отправляем сообщение на Кафку
+		// partition вернет раздел топика в которое улетело сообщение
+		// offset вернет позицию сообщения в партиции
+		partition, offset, err := producer.SendMessage(message)
+		if err != nil {
+			return i, err
+		}
+		log.Printf("Message sent to partition %d at offset %d\n", partition, offset)
+	}
+	return len(messages), nil
+}
+
+func (this *KafkaPublisher) Close() error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	if this.producer == nil {
+		return nil
+	}
+	return this.producer.Close()
+}
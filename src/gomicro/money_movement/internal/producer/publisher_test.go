@@ -0,0 +1,36 @@
+package producer
+
+import (
+	"testing"
+)
+
+func TestKafkaPublisherWithoutBrokers(t *testing.T) {
+	// Порт без брокера: создание продюсера не должно зависеть от Кафки
+	publisher, err := NewKafkaPublisher(Config{Brokers: []string{"127.0.0.1:1"}})
+	if err != nil {
+		t.Fatalf("NewKafkaPublisher: %v", err)
+	}
+	publisher.config.Metadata.Retry.Max = 0
+
+	sent, err := publisher.Publish([]Message{{Topic: "ledger", Key: "pid", Value: []byte("{}")}})
+	if err == nil || sent != 0 {
+		t.Fatalf("Publish = (%d, %v), want (0, error)", sent, err)
+	}
+	if err = publisher.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestKafkaPublisherInvalidConfig(t *testing.T) {
+	tests := []Config{
+		{},
+		{Brokers: []string{"kafka:9092"}, RequiredAcks: "some"},
+		{Brokers: []string{"kafka:9092"}, Compression: "brotli"},
+		{Brokers: []string{"kafka:9092"}, RequiredAcks: "leader", Idempotent: true},
+	}
+	for _, config := range tests {
+		if _, err := NewKafkaPublisher(config); err == nil {
+			t.Errorf("NewKafkaPublisher(%+v): expected error", config)
+		}
+	}
+}